@@ -3,12 +3,14 @@ package mongo
 import (
 	"context"
 
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
+
 	"go.mongodb.org/mongo-driver/bson"
 )
 
 type IRepository interface {
 	FindOne(context.Context, string, *MongoQueryBuilder, interface{}) error
-	FindMany(context.Context, string, *MongoQueryBuilder, interface{}) (int, error)
+	FindMany(context.Context, string, *MongoQueryBuilder, interface{}) (pagination.PageInfo, error)
 	UpdateOne(context.Context, string, *MongoQueryBuilder) (map[string]interface{}, error)
 	InsertOne(context.Context, string, bson.M) (map[string]interface{}, error)
 	DeleteOne(context.Context, string, *MongoQueryBuilder) (map[string]interface{}, error)
@@ -0,0 +1,133 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Keyset switches the builder to keyset pagination driven by its sort keys.
+// token is a cursor returned in PageInfo by a previous FindMany, or "" for
+// the first page. The sort keys must identify a document uniquely, e.g. by
+// ending with _id.
+func (b *MongoQueryBuilder) Keyset(codec *pagination.CursorCodec, token string, size int64) *MongoQueryBuilder {
+	keyset, err := pagination.NewKeyset(codec, token, size)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.keyset = keyset
+	return b
+}
+
+// BuildFilter returns the filter set with Where, combined with the keyset
+// position when keyset pagination is used.
+func (b *MongoQueryBuilder) BuildFilter() bson.M {
+	seek := b.seekFilter()
+	if seek == nil {
+		return b.filter
+	}
+	if len(b.filter) == 0 {
+		return seek
+	}
+	return bson.M{"$and": bson.A{b.filter, seek}}
+}
+
+// seekFilter renders the keyset position as
+// `{$or: [{k1: {$gt: v1}}, {k1: v1, k2: {$gt: v2}}, ...]}`, with $lt for
+// descending keys and every comparison inverted for backward pages.
+func (b *MongoQueryBuilder) seekFilter() bson.M {
+	if b.keyset == nil {
+		return nil
+	}
+	if err := b.keyset.CheckKeys(b.sortKeySpec()); err != nil {
+		b.setErr(err)
+		return nil
+	}
+	values := b.keyset.Cursor.Values
+	if len(values) == 0 {
+		return nil
+	}
+
+	branches := make(bson.A, 0, len(b.sort))
+	for i, key := range b.sort {
+		branch := bson.D{}
+		for j := 0; j < i; j++ {
+			branch = append(branch, bson.E{Key: b.sort[j].Key, Value: values[j]})
+		}
+
+		op := "$gt"
+		if (sortDirection(key.Value) < 0) != b.keyset.Backward() {
+			op = "$lt"
+		}
+		branch = append(branch, bson.E{Key: key.Key, Value: bson.M{op: values[i]}})
+		branches = append(branches, branch)
+	}
+	return bson.M{"$or": branches}
+}
+
+func (b *MongoQueryBuilder) sortKeySpec() []pagination.SortKey {
+	keys := make([]pagination.SortKey, len(b.sort))
+	for i, key := range b.sort {
+		keys[i] = pagination.SortKey{Column: key.Key, Desc: sortDirection(key.Value) < 0}
+	}
+	return keys
+}
+
+// sortKeys returns the sort keys, inverted when a backward keyset page is
+// requested.
+func (b *MongoQueryBuilder) sortKeys() bson.D {
+	if b.keyset == nil || !b.keyset.Backward() {
+		return b.sort
+	}
+
+	keys := make(bson.D, len(b.sort))
+	for i, key := range b.sort {
+		keys[i] = bson.E{Key: key.Key, Value: -sortDirection(key.Value)}
+	}
+	return keys
+}
+
+// keysetValues extracts the sort key values of a decoded document by
+// marshalling it back to BSON, so that bson struct tags are honoured.
+func (b *MongoQueryBuilder) keysetValues(item interface{}) ([]interface{}, error) {
+	raw, err := bson.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(b.sort))
+	for i, key := range b.sort {
+		rawValue, err := bson.Raw(raw).LookupErr(strings.Split(key.Key, ".")...)
+		if err != nil {
+			return nil, fmt.Errorf("sort key %q: %w", key.Key, err)
+		}
+		if err := rawValue.Unmarshal(&values[i]); err != nil {
+			return nil, fmt.Errorf("sort key %q: %w", key.Key, err)
+		}
+	}
+	return values, nil
+}
+
+func sortDirection(v interface{}) int {
+	switch d := v.(type) {
+	case int:
+		return sign(int64(d))
+	case int32:
+		return sign(int64(d))
+	case int64:
+		return sign(d)
+	case float64:
+		return sign(int64(d))
+	}
+	return 1
+}
+
+func sign(n int64) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
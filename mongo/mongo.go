@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -45,10 +46,17 @@ func (r *MongoRepository) FindOne(ctx context.Context, collectionName string, qb
 	return nil
 }
 
-func (r *MongoRepository) FindMany(ctx context.Context, collectionName string, qb *MongoQueryBuilder, results interface{}) (int, error) {
+func (r *MongoRepository) FindMany(ctx context.Context, collectionName string, qb *MongoQueryBuilder, results interface{}) (pagination.PageInfo, error) {
+	if err := qb.Err(); err != nil {
+		return pagination.PageInfo{}, fmt.Errorf("invalid query: %w", err)
+	}
+	if qb.keyset != nil {
+		return r.findKeyset(ctx, collectionName, qb, results)
+	}
+
 	totalCount, err := r.db.Collection(collectionName).CountDocuments(ctx, qb.filter)
 	if err != nil {
-		return 0, fmt.Errorf("error fetching total count: %w", err)
+		return pagination.PageInfo{}, fmt.Errorf("error fetching total count: %w", err)
 	}
 
 	projection := qb.BuildProjection()
@@ -56,14 +64,42 @@ func (r *MongoRepository) FindMany(ctx context.Context, collectionName string, q
 
 	cursor, err := r.db.Collection(collectionName).Find(ctx, qb.filter, findOptions.SetProjection(projection))
 	if err != nil {
-		return 0, fmt.Errorf("error finding many records: %w", err)
+		return pagination.PageInfo{}, fmt.Errorf("error finding many records: %w", err)
 	}
 	defer cursor.Close(ctx)
 
 	if err := cursor.All(ctx, results); err != nil {
-		return 0, fmt.Errorf("error decoding many records: %w", err)
+		return pagination.PageInfo{}, fmt.Errorf("error decoding many records: %w", err)
+	}
+	return pagination.NewPageInfo(totalCount, qb.skip, qb.limit), nil
+}
+
+func (r *MongoRepository) findKeyset(ctx context.Context, collectionName string, qb *MongoQueryBuilder, results interface{}) (pagination.PageInfo, error) {
+	filter := qb.BuildFilter()
+	findOptions := qb.BuildFindOptions()
+	if err := qb.Err(); err != nil {
+		return pagination.PageInfo{}, fmt.Errorf("invalid query: %w", err)
 	}
-	return int(totalCount), nil
+
+	return qb.keyset.Query(results, func(results interface{}) error {
+		cursor, err := r.db.Collection(collectionName).Find(ctx, filter, findOptions.SetProjection(qb.BuildProjection()))
+		if err != nil {
+			return fmt.Errorf("error finding many records: %w", err)
+		}
+		defer cursor.Close(ctx)
+
+		if err := cursor.All(ctx, results); err != nil {
+			return fmt.Errorf("error decoding many records: %w", err)
+		}
+		return nil
+	}, qb.keysetValues)
+}
+
+// FindPage is FindMany returning a pagination.PagedResult.
+func FindPage[T any](ctx context.Context, repo IRepository, collectionName string, qb *MongoQueryBuilder) (pagination.PagedResult[T], error) {
+	return pagination.Collect[T](func(results interface{}) (pagination.PageInfo, error) {
+		return repo.FindMany(ctx, collectionName, qb, results)
+	})
 }
 
 func (r *MongoRepository) UpdateOne(ctx context.Context, collectionName string, qb *MongoQueryBuilder) (map[string]interface{}, error) {
@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -12,6 +13,8 @@ type MongoQueryBuilder struct {
 	limit   int64
 	skip    int64
 	sort    bson.D
+	keyset  *pagination.Keyset
+	err     error
 }
 
 func NewMongoQueryBuilder() *MongoQueryBuilder {
@@ -38,11 +41,32 @@ func (b *MongoQueryBuilder) Skip(skip int64) *MongoQueryBuilder {
 	return b
 }
 
+// Page selects the 1-based page number of the given size.
+func (b *MongoQueryBuilder) Page(number, size int64) *MongoQueryBuilder {
+	return b.Paginate(pagination.Page{Number: number, Size: size})
+}
+
+func (b *MongoQueryBuilder) Paginate(p pagination.Paginator) *MongoQueryBuilder {
+	b.skip, b.limit = p.Window()
+	return b
+}
+
 func (b *MongoQueryBuilder) Sort(sort bson.D) *MongoQueryBuilder {
 	b.sort = sort
 	return b
 }
 
+// Err returns the first error recorded while the query was being composed.
+func (b *MongoQueryBuilder) Err() error {
+	return b.err
+}
+
+func (b *MongoQueryBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
 func (b *MongoQueryBuilder) BuildProjection() bson.M {
 	if len(b.columns) == 0 {
 		return nil
@@ -57,14 +81,16 @@ func (b *MongoQueryBuilder) BuildProjection() bson.M {
 
 func (b *MongoQueryBuilder) BuildFindOptions() *options.FindOptions {
 	opts := options.Find()
-	if b.limit > 0 {
+	if b.keyset != nil {
+		opts.SetLimit(b.keyset.Fetch())
+	} else if b.limit > 0 {
 		opts.SetLimit(b.limit)
 	}
-	if b.skip > 0 {
+	if b.skip > 0 && b.keyset == nil {
 		opts.SetSkip(b.skip)
 	}
 	if len(b.sort) > 0 {
-		opts.SetSort(b.sort)
+		opts.SetSort(b.sortKeys())
 	}
 	return opts
 }
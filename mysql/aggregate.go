@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type aggregate struct {
+	fn       string
+	column   string
+	alias    string
+	distinct bool
+}
+
+// GroupBy appends columns to the GROUP BY clause.
+func (b *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Having adds a HAVING condition joined with AND. It accepts the same raw
+// fragments and Predicates as Where; aggregate aliases may be used as
+// column names.
+func (b *QueryBuilder) Having(condition interface{}, args ...interface{}) *QueryBuilder {
+	if node, ok := b.newCondition("AND", false, condition, args); ok {
+		b.having = append(b.having, node)
+	}
+	return b
+}
+
+func (b *QueryBuilder) OrHaving(condition interface{}, args ...interface{}) *QueryBuilder {
+	if node, ok := b.newCondition("OR", false, condition, args); ok {
+		b.having = append(b.having, node)
+	}
+	return b
+}
+
+// Count selects COUNT(column) AS alias. Use "*" to count rows.
+func (b *QueryBuilder) Count(column, alias string) *QueryBuilder {
+	return b.addAggregate("COUNT", column, alias, false)
+}
+
+func (b *QueryBuilder) CountDistinct(column, alias string) *QueryBuilder {
+	return b.addAggregate("COUNT", column, alias, true)
+}
+
+func (b *QueryBuilder) Sum(column, alias string) *QueryBuilder {
+	return b.addAggregate("SUM", column, alias, false)
+}
+
+func (b *QueryBuilder) Avg(column, alias string) *QueryBuilder {
+	return b.addAggregate("AVG", column, alias, false)
+}
+
+func (b *QueryBuilder) Min(column, alias string) *QueryBuilder {
+	return b.addAggregate("MIN", column, alias, false)
+}
+
+func (b *QueryBuilder) Max(column, alias string) *QueryBuilder {
+	return b.addAggregate("MAX", column, alias, false)
+}
+
+func (b *QueryBuilder) addAggregate(fn, column, alias string, distinct bool) *QueryBuilder {
+	b.aggregates = append(b.aggregates, aggregate{
+		fn:       fn,
+		column:   column,
+		alias:    alias,
+		distinct: distinct,
+	})
+	return b
+}
+
+func (b *QueryBuilder) isGrouped() bool {
+	return len(b.groupBy) > 0 || len(b.having) > 0
+}
+
+func (b *QueryBuilder) isAggregateAlias(name string) bool {
+	for _, agg := range b.aggregates {
+		if agg.alias != "" && strings.EqualFold(agg.alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *QueryBuilder) buildAggregate(agg aggregate) string {
+	column := "*"
+	if agg.column != "*" || agg.fn != "COUNT" || agg.distinct {
+		column = b.quoteColumn(agg.column)
+	}
+	if agg.distinct {
+		column = "DISTINCT " + column
+	}
+
+	expr := fmt.Sprintf("%s(%s)", agg.fn, column)
+	if agg.alias == "" {
+		return expr
+	}
+
+	alias, err := parseIdentifier(agg.alias, false)
+	if err != nil || len(alias) != 1 {
+		b.setErr(fmt.Errorf("%q: %w", agg.alias, ErrInvalidIdentifier))
+		return expr
+	}
+	return fmt.Sprintf("%s AS %s", expr, quoteParts(alias))
+}
+
+func (b *QueryBuilder) buildGroupByClause() string {
+	if len(b.groupBy) == 0 {
+		return ""
+	}
+	columns := make([]string, len(b.groupBy))
+	for i, column := range b.groupBy {
+		columns[i] = b.quoteColumn(column)
+	}
+	return fmt.Sprintf("GROUP BY %s", strings.Join(columns, ", "))
+}
+
+func (b *QueryBuilder) buildHavingClause() (string, []interface{}) {
+	expr, args := b.buildConditions(b.having)
+	if expr == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("HAVING %s", expr), args
+}
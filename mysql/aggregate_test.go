@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAggregates(t *testing.T) {
+	tests := []struct {
+		name  string
+		qb    *QueryBuilder
+		count bool
+		want  string
+		args  []interface{}
+		err   error
+	}{
+		{
+			name: "group by with having",
+			qb: NewQueryBuilder().
+				Select([]string{"course_id"}).
+				Count("*", "enrolled").
+				Avg("grade", "average").
+				Where(Eq("status", "active")).
+				GroupBy("course_id").
+				Having(Gt("enrolled", 10)).
+				OrHaving("average >= ?", 90),
+			want: "SELECT `course_id`, COUNT(*) AS `enrolled`, AVG(`grade`) AS `average` FROM `enrollments` WHERE `status` = ? GROUP BY `course_id` HAVING `enrolled` > ? OR (average >= ?)",
+			args: []interface{}{"active", 10, 90},
+		},
+		{
+			name: "distinct count without alias",
+			qb:   NewQueryBuilder().CountDistinct("student_id", "").Sum("credits", "credits").Min("e.grade", "low").Max("e.grade", "high"),
+			want: "SELECT COUNT(DISTINCT `student_id`), SUM(`credits`) AS `credits`, MIN(`e`.`grade`) AS `low`, MAX(`e`.`grade`) AS `high` FROM `enrollments`",
+			args: []interface{}{},
+		},
+		{
+			name: "aggregate aliases pass the validator",
+			qb: NewQueryBuilder().
+				AllowColumns("course_id").
+				Select([]string{"course_id"}).
+				Count("*", "enrolled").
+				GroupBy("course_id").
+				Having(Gte("enrolled", 3)),
+			want: "SELECT `course_id`, COUNT(*) AS `enrolled` FROM `enrollments` GROUP BY `course_id` HAVING `enrolled` >= ?",
+			args: []interface{}{3},
+		},
+		{
+			name:  "predicates take columns, not expressions",
+			qb:    NewQueryBuilder().Select([]string{"course_id"}).Where(Eq("status", "active")).GroupBy("course_id").Having(Gt("COUNT(*)", 1)),
+			count: true,
+			err:   ErrInvalidIdentifier,
+		},
+		{
+			name:  "count of groups",
+			qb:    NewQueryBuilder().Select([]string{"course_id"}).Count("*", "enrolled").Where(Eq("status", "active")).GroupBy("course_id").Having(Gt("enrolled", 1)),
+			count: true,
+			want:  "SELECT COUNT(*) FROM (SELECT `course_id`, COUNT(*) AS `enrolled` FROM `enrollments` WHERE `status` = ? GROUP BY `course_id` HAVING `enrolled` > ?) AS `counted`",
+			args:  []interface{}{"active", 1},
+		},
+		{
+			name: "invalid alias",
+			qb:   NewQueryBuilder().Count("*", "n; DROP TABLE x"),
+			err:  ErrInvalidIdentifier,
+		},
+		{
+			name: "group by column not allowed",
+			qb:   NewQueryBuilder().AllowColumns("course_id").GroupBy("password"),
+			err:  ErrIdentifierNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		build := tt.qb.BuildSelectManyQuery
+		if tt.count {
+			build = tt.qb.BuildCountQuery
+		}
+		query, args := build("enrollments")
+		if !errors.Is(tt.qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.qb.Err(), tt.err)
+			continue
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if tt.err == nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
+)
+
+// ErrMissingResultSet is returned by Call when a procedure returns fewer
+// result sets than destinations.
+var ErrMissingResultSet = errors.New("procedure returned fewer result sets than destinations")
+
+// resultSet keeps the scanner from closing the rows between result sets.
+type resultSet struct {
+	*sql.Rows
+}
+
+func (resultSet) Close() error {
+	return nil
+}
+
+// Call runs the stored procedure proc and scans its result sets, in order,
+// into dests. A destination is a slice pointer for all rows, including
+// *[]map[string]interface{}, or a struct pointer for the first row; a nil
+// destination skips its result set.
+//
+// The driver does not support OUT parameters directly, so they are passed
+// as sql.Out args: each is bound to a session variable that is read back
+// into Dest once the result sets are scanned, and set from Dest first when
+// In is true.
+//
+//	var total int
+//	var grades []Grade
+//	err := repo.Call(ctx, "course_grades", []interface{}{courseID, sql.Out{Dest: &total}}, &grades)
+func (r *MySQLRepository) Call(ctx context.Context, proc string, args []interface{}, dests ...interface{}) error {
+	name, err := QuoteIdentifier(proc)
+	if err != nil {
+		return fmt.Errorf("invalid procedure: %w", err)
+	}
+
+	// Session variables only live on one connection, so pin one outside
+	// of a transaction.
+	var executor queryExecutor = r.Tx
+	if r.Tx == nil {
+		conn, err := r.db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get connection: %w", err)
+		}
+		defer conn.Close()
+		executor = conn
+	}
+
+	placeholders := make([]string, len(args))
+	var inArgs []interface{}
+	var outVars []string
+	var outDests []interface{}
+	for i, arg := range args {
+		out, ok := arg.(sql.Out)
+		if !ok {
+			placeholders[i] = "?"
+			inArgs = append(inArgs, arg)
+			continue
+		}
+
+		variable := fmt.Sprintf("@_out%d", len(outVars))
+		if out.In {
+			value := reflect.ValueOf(out.Dest)
+			if value.Kind() != reflect.Ptr || value.IsNil() {
+				return fmt.Errorf("OUT parameter %d: Dest must be a non-nil pointer", i)
+			}
+			if _, err := executor.ExecContext(ctx, "SET "+variable+" = ?", value.Elem().Interface()); err != nil {
+				return fmt.Errorf("error setting INOUT parameter %d: %w", i, err)
+			}
+		}
+		placeholders[i] = variable
+		outVars = append(outVars, variable)
+		outDests = append(outDests, out.Dest)
+	}
+
+	query := fmt.Sprintf("CALL %s(%s)", name, strings.Join(placeholders, ", "))
+	rows, err := executor.QueryContext(ctx, query, inArgs...)
+	if err != nil {
+		return fmt.Errorf("error calling procedure: %w", err)
+	}
+	if err := r.scanResultSets(rows, dests); err != nil {
+		rows.Close()
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("error calling procedure: %w", err)
+	}
+
+	if len(outVars) > 0 {
+		query := "SELECT " + strings.Join(outVars, ", ")
+		if err := executor.QueryRowContext(ctx, query).Scan(outDests...); err != nil {
+			return fmt.Errorf("failed to read OUT parameters: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *MySQLRepository) scanResultSets(rows *sql.Rows, dests []interface{}) error {
+	for i, dest := range dests {
+		if i > 0 && !rows.NextResultSet() {
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("failed to read result set %d: %w", i, err)
+			}
+			return fmt.Errorf("result set %d: %w", i, ErrMissingResultSet)
+		}
+		if dest == nil {
+			continue
+		}
+		if err := r.scanDest(dest, resultSet{rows}); err != nil {
+			return fmt.Errorf("failed to scan result set %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// scanDest scans all rows into a slice pointer and the first row into any
+// other pointer. An empty result set leaves a single row destination as is.
+func (r *MySQLRepository) scanDest(dest interface{}, rows sqlscan.RowsScanner) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Slice {
+		return r.scanRows(dest, rows)
+	}
+
+	var err error
+	if m, ok := dest.(*map[string]interface{}); ok {
+		if r.scanner != nil {
+			*m, err = r.scanner.RowMap(rows)
+		} else {
+			*m, err = sqlscan.RowMap(rows)
+		}
+	} else {
+		err = r.scanRow(dest, rows)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
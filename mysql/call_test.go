@@ -0,0 +1,185 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeDB is a database/sql connector that records the statements it runs.
+// Session variables set with `SET @v = ?` are kept and read back by
+// `SELECT @a, @b`; CALL runs procedure and returns resultSets.
+type fakeDB struct {
+	statements []fakeStatement
+	vars       map[string]driver.Value
+	resultSets []fakeResultSet
+	procedure  func(vars map[string]driver.Value)
+}
+
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+}
+
+type fakeResultSet struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values := c.record(query, args)
+	if name, ok := strings.CutPrefix(query, "SET "); ok {
+		name, _, _ = strings.Cut(name, " = ")
+		c.db.vars[name] = values[0]
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args)
+	if names, ok := strings.CutPrefix(query, "SELECT "); ok {
+		var row []driver.Value
+		for _, name := range strings.Split(names, ", ") {
+			row = append(row, c.db.vars[name])
+		}
+		return &fakeDriverRows{sets: []fakeResultSet{{columns: strings.Split(names, ", "), rows: [][]driver.Value{row}}}}, nil
+	}
+	if c.db.procedure != nil {
+		c.db.procedure(c.db.vars)
+	}
+	return &fakeDriverRows{sets: c.db.resultSets}, nil
+}
+
+func (c *fakeConn) record(query string, args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.db.statements = append(c.db.statements, fakeStatement{query: query, args: values})
+	return values
+}
+
+type fakeDriverRows struct {
+	sets     []fakeResultSet
+	set, row int
+}
+
+func (r *fakeDriverRows) Columns() []string { return r.sets[r.set].columns }
+func (r *fakeDriverRows) Close() error      { return nil }
+
+func (r *fakeDriverRows) Next(dest []driver.Value) error {
+	rows := r.sets[r.set].rows
+	if r.row >= len(rows) {
+		return io.EOF
+	}
+	copy(dest, rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *fakeDriverRows) HasNextResultSet() bool { return r.set+1 < len(r.sets) }
+
+func (r *fakeDriverRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.row = 0
+	return nil
+}
+
+func TestCall(t *testing.T) {
+	type grade struct {
+		StudentID int64  `db:"student_id"`
+		Grade     string `db:"grade"`
+	}
+	type summary struct {
+		Average float64 `db:"average"`
+	}
+
+	db := &fakeDB{
+		vars: map[string]driver.Value{},
+		resultSets: []fakeResultSet{
+			{columns: []string{"student_id", "grade"}, rows: [][]driver.Value{{int64(1), "A"}, {int64(2), "B"}}},
+			{columns: []string{"note"}, rows: [][]driver.Value{{"skipped"}}},
+			{columns: []string{"average"}, rows: [][]driver.Value{{3.5}}},
+		},
+		procedure: func(vars map[string]driver.Value) {
+			vars["@_out0"] = int64(2)
+			vars["@_out1"] = vars["@_out1"].(int64) * 2
+		},
+	}
+	repo := &MySQLRepository{db: sql.OpenDB(db)}
+
+	var total int
+	attempts := 5
+	var grades []grade
+	var avg summary
+	args := []interface{}{7, sql.Out{Dest: &total}, sql.Out{Dest: &attempts, In: true}}
+	if err := repo.Call(context.Background(), "school.course_grades", args, &grades, nil, &avg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []fakeStatement{
+		{query: "SET @_out1 = ?", args: []driver.Value{int64(5)}},
+		{query: "CALL `school`.`course_grades`(?, @_out0, @_out1)", args: []driver.Value{int64(7)}},
+		{query: "SELECT @_out0, @_out1", args: []driver.Value{}},
+	}
+	if !reflect.DeepEqual(db.statements, want) {
+		t.Errorf("statements = %v, want %v", db.statements, want)
+	}
+	if total != 2 || attempts != 10 {
+		t.Errorf("OUT parameters = %d, %d, want 2, 10", total, attempts)
+	}
+	if wantGrades := []grade{{1, "A"}, {2, "B"}}; !reflect.DeepEqual(grades, wantGrades) {
+		t.Errorf("grades = %v, want %v", grades, wantGrades)
+	}
+	if avg.Average != 3.5 {
+		t.Errorf("average = %v, want 3.5", avg.Average)
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	set := fakeResultSet{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	var ids, more []int64
+	tests := []struct {
+		name  string
+		proc  string
+		args  []interface{}
+		dests []interface{}
+		err   error
+		runs  int
+	}{
+		{name: "invalid procedure", proc: "grades; DROP TABLE x", err: ErrInvalidIdentifier},
+		{name: "INOUT without pointer", proc: "grades", args: []interface{}{sql.Out{Dest: 1, In: true}}},
+		{name: "missing result set", proc: "grades", dests: []interface{}{&ids, &more}, err: ErrMissingResultSet, runs: 1},
+	}
+
+	for _, tt := range tests {
+		db := &fakeDB{vars: map[string]driver.Value{}, resultSets: []fakeResultSet{set}}
+		repo := &MySQLRepository{db: sql.OpenDB(db)}
+		err := repo.Call(context.Background(), tt.proc, tt.args, tt.dests...)
+		if err == nil || tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+		if len(db.statements) != tt.runs {
+			t.Errorf("%s: ran %d statements, want %d", tt.name, len(db.statements), tt.runs)
+		}
+	}
+}
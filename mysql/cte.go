@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeysetUnion is returned when keyset pagination is combined with UNION.
+// The seek condition would only filter the first SELECT; select from the
+// union through FromSubquery instead.
+var ErrKeysetUnion = errors.New("keyset pagination cannot be applied to a union")
+
+type commonTableExpression struct {
+	name      string
+	columns   []string
+	recursive bool
+	query     *QueryBuilder
+}
+
+type union struct {
+	all   bool
+	query *QueryBuilder
+}
+
+// With adds a common table expression, `WITH name (columns) AS (SELECT ...)`,
+// that the query can select from or join by name.
+func (b *QueryBuilder) With(name string, sub *QueryBuilder, columns ...string) *QueryBuilder {
+	b.ctes = append(b.ctes, commonTableExpression{name: name, columns: columns, query: sub})
+	return b
+}
+
+// WithRecursive adds a recursive common table expression. sub is usually an
+// anchor query combined with UnionAll with a query that joins name, e.g. to
+// walk course prerequisites.
+func (b *QueryBuilder) WithRecursive(name string, sub *QueryBuilder, columns ...string) *QueryBuilder {
+	b.ctes = append(b.ctes, commonTableExpression{name: name, columns: columns, recursive: true, query: sub})
+	return b
+}
+
+// Union appends `UNION SELECT ...`. The ORDER BY and LIMIT of b apply to the
+// combined result; those of other only apply to its own rows.
+func (b *QueryBuilder) Union(other *QueryBuilder) *QueryBuilder {
+	b.unions = append(b.unions, union{query: other})
+	return b
+}
+
+// UnionAll appends `UNION ALL SELECT ...`, keeping duplicate rows.
+func (b *QueryBuilder) UnionAll(other *QueryBuilder) *QueryBuilder {
+	b.unions = append(b.unions, union{all: true, query: other})
+	return b
+}
+
+// buildWithClause renders the WITH clause. MySQL puts RECURSIVE on the
+// clause rather than on each expression, so a single recursive expression
+// makes the whole clause recursive.
+func (b *QueryBuilder) buildWithClause() (string, []interface{}) {
+	if len(b.ctes) == 0 {
+		return "", nil
+	}
+
+	var args []interface{}
+	recursive := false
+	expressions := make([]string, len(b.ctes))
+	for i, cte := range b.ctes {
+		nameParts, err := parseIdentifier(cte.name, false)
+		if err != nil || len(nameParts) != 1 {
+			b.setErr(fmt.Errorf("common table expression %q: %w", cte.name, ErrInvalidIdentifier))
+			return "", nil
+		}
+
+		expression := quoteParts(nameParts)
+		if len(cte.columns) > 0 {
+			columns := make([]string, len(cte.columns))
+			for j, column := range cte.columns {
+				parts, err := parseIdentifier(column, false)
+				if err != nil || len(parts) != 1 {
+					b.setErr(fmt.Errorf("common table expression column %q: %w", column, ErrInvalidIdentifier))
+					return "", nil
+				}
+				columns[j] = quoteParts(parts)
+			}
+			expression += " (" + strings.Join(columns, ", ") + ")"
+		}
+
+		query, queryArgs := b.buildSubquery(cte.query)
+		expressions[i] = fmt.Sprintf("%s AS (%s)", expression, query)
+		args = append(args, queryArgs...)
+		recursive = recursive || cte.recursive
+	}
+
+	keyword := "WITH "
+	if recursive {
+		keyword = "WITH RECURSIVE "
+	}
+	return keyword + strings.Join(expressions, ", "), args
+}
+
+// buildUnionClause renders the UNION parts that follow the first SELECT.
+// Parts with their own WITH, UNION, ORDER BY or LIMIT are parenthesized so
+// that those clauses do not leak into the combined query.
+func (b *QueryBuilder) buildUnionClause() (string, []interface{}) {
+	if len(b.unions) == 0 {
+		return "", nil
+	}
+	if b.keyset != nil {
+		b.setErr(ErrKeysetUnion)
+		return "", nil
+	}
+
+	var args []interface{}
+	parts := make([]string, len(b.unions))
+	for i, u := range b.unions {
+		query, queryArgs := b.buildSubquery(u.query)
+		if u.query != nil && u.query.isCompound() {
+			query = "(" + query + ")"
+		}
+
+		keyword := "UNION "
+		if u.all {
+			keyword = "UNION ALL "
+		}
+		parts[i] = keyword + query
+		args = append(args, queryArgs...)
+	}
+	return strings.Join(parts, " "), args
+}
+
+func (b *QueryBuilder) isCompound() bool {
+	return len(b.ctes) > 0 || len(b.unions) > 0 || len(b.orderBy) > 0 ||
+		b.limit > 0 || b.offset > 0 || b.page > 0 || b.keyset != nil
+}
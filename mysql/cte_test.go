@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCommonTableExpressionsAndUnions(t *testing.T) {
+	tests := []struct {
+		name  string
+		qb    *QueryBuilder
+		count bool
+		want  string
+		args  []interface{}
+		err   error
+	}{
+		{
+			name: "args follow the clause order",
+			qb: NewQueryBuilder().
+				With("active", NewQueryBuilder().Select([]string{"student_id"}).From("enrollments").Where(Eq("status", "active")), "id").
+				InnerJoin("active a", "a.id = s.id AND s.grade > ?", 5).
+				Where(Eq("s.school_id", 4)).
+				Union(NewQueryBuilder().Select([]string{"*"}).From("alumni").Where(Eq("school_id", 8))).
+				OrderByAsc("name").
+				Limit(10),
+			want: "WITH `active` (`id`) AS (SELECT `student_id` FROM `enrollments` WHERE `status` = ?) " +
+				"SELECT * FROM `students` AS `s` INNER JOIN `active` AS `a` ON a.id = s.id AND s.grade > ? WHERE `s`.`school_id` = ? " +
+				"UNION SELECT * FROM `alumni` WHERE `school_id` = ? ORDER BY `name` ASC LIMIT 10",
+			args: []interface{}{"active", 5, 4, 8},
+		},
+		{
+			name: "recursive",
+			qb: NewQueryBuilder().
+				WithRecursive("prerequisites",
+					NewQueryBuilder().Select([]string{"id", "requires_id"}).From("courses").Where(Eq("id", 12)).
+						UnionAll(NewQueryBuilder().Select([]string{"c.id", "c.requires_id"}).From("courses c").InnerJoin("prerequisites p", "c.id = p.requires_id"))).
+				With("limited", NewQueryBuilder().From("courses").Where(Lt("level", 3))),
+			want: "WITH RECURSIVE `prerequisites` AS (SELECT `id`, `requires_id` FROM `courses` WHERE `id` = ? " +
+				"UNION ALL SELECT `c`.`id`, `c`.`requires_id` FROM `courses` AS `c` INNER JOIN `prerequisites` AS `p` ON c.id = p.requires_id), " +
+				"`limited` AS (SELECT * FROM `courses` WHERE `level` < ?) SELECT * FROM `students` AS `s`",
+			args: []interface{}{12, 3},
+		},
+		{
+			name: "paginated union parts are parenthesized",
+			qb: NewQueryBuilder().Where(Eq("school_id", 4)).
+				UnionAll(NewQueryBuilder().From("alumni").Where(Eq("school_id", 8)).OrderByDesc("graduated_at").Limit(5)),
+			want: "SELECT * FROM `students` AS `s` WHERE `school_id` = ? UNION ALL (SELECT * FROM `alumni` WHERE `school_id` = ? ORDER BY `graduated_at` DESC LIMIT 5)",
+			args: []interface{}{4, 8},
+		},
+		{
+			name: "count of a union",
+			qb: NewQueryBuilder().With("a", NewQueryBuilder().From("alumni").Where(Eq("school_id", 8))).
+				Where(Eq("school_id", 4)).
+				Union(NewQueryBuilder().From("a")),
+			count: true,
+			want:  "WITH `a` AS (SELECT * FROM `alumni` WHERE `school_id` = ?) SELECT COUNT(*) FROM (SELECT * FROM `students` AS `s` WHERE `school_id` = ? UNION SELECT * FROM `a`) AS `counted`",
+			args:  []interface{}{8, 4},
+		},
+		{
+			name: "invalid name",
+			qb:   NewQueryBuilder().With("a b", NewQueryBuilder().From("alumni")),
+			err:  ErrInvalidIdentifier,
+		},
+		{
+			name: "invalid column",
+			qb:   NewQueryBuilder().With("a", NewQueryBuilder().From("alumni"), "id, (SELECT 1)"),
+			err:  ErrInvalidIdentifier,
+		},
+		{
+			name: "union part without table",
+			qb:   NewQueryBuilder().Union(NewQueryBuilder()),
+			err:  ErrMissingTable,
+		},
+	}
+
+	for _, tt := range tests {
+		build := tt.qb.BuildSelectManyQuery
+		if tt.count {
+			build = tt.qb.BuildCountQuery
+		}
+		query, args := build("students s")
+		if !errors.Is(tt.qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.qb.Err(), tt.err)
+			continue
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if tt.err == nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
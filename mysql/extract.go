@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
+)
+
+// ErrNoSetColumns is returned when an UPDATE has no columns to set.
+var ErrNoSetColumns = errors.New("update has no columns to set")
+
+// UpdateOptions narrows the columns ExtractFieldsForUpdate sets.
+type UpdateOptions struct {
+	// Columns, when not empty, limits the update to these columns.
+	Columns []string
+	// Skip lists columns that are never set, on top of readonly fields.
+	Skip []string
+}
+
+// ExtractFieldsForUpdate adds a Set for every `db` tagged field of data, a
+// pointer to a struct. Nil pointer fields are left out, so a struct of
+// pointers describes a partial update. Tag options control the rest:
+//
+//	Status    string    `db:"status,readonly"`       // never updated
+//	CreatedBy int64     `db:"created_by,insertonly"` // never updated
+//	Title     string    `db:"title,omitempty"`       // skipped when "", 0, false or nil
+//	Due       time.Time `db:"due_at,omitzero"`       // skipped when IsZero() reports true
+//
+// Fields tagged autoincrement are never updated either, nor are fields named
+// id, created_at or updated_at without tag options, matching the insert.
+func (qb *QueryBuilder) ExtractFieldsForUpdate(data interface{}, opts UpdateOptions) error {
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("data must be a pointer to a struct")
+	}
+
+	val = val.Elem()
+
+	only := columnSet(opts.Columns)
+	skip := columnSet(opts.Skip)
+	for _, field := range sqlscan.TaggedFields(val.Type()) {
+		if field.ReadOnly || field.AutoIncrement || field.InsertOnly || isLegacySkip(field) {
+			continue
+		}
+		if _, ok := skip[field.Name]; ok {
+			continue
+		}
+		if _, ok := only[field.Name]; len(only) > 0 && !ok {
+			continue
+		}
+		value, ok := fieldValue(val, field)
+		if !ok || skipValue(field.TagOptions, value) {
+			continue
+		}
+		arg, err := columnValue(field, value)
+		if err != nil {
+			return err
+		}
+		qb.Set(fieldColumn(field), arg)
+	}
+	return nil
+}
+
+// fieldValue returns the value of field in val, dereferencing pointers. It
+// reports false for nil pointers, including nil embedded struct pointers.
+func fieldValue(val reflect.Value, field sqlscan.Field) (reflect.Value, bool) {
+	value, err := val.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+// fieldColumn returns the column reference of field. A dotted name, as given
+// to the fields of a nested struct tagged dotted, is one column name rather
+// than a table qualified reference, so it is quoted as a whole.
+func fieldColumn(field sqlscan.Field) string {
+	if !strings.Contains(field.Name, ".") {
+		return field.Name
+	}
+	return "`" + strings.ReplaceAll(field.Name, "`", "``") + "`"
+}
+
+// columnValue returns the query argument for the value of field, encoding
+// fields tagged json.
+func columnValue(field sqlscan.Field, value reflect.Value) (interface{}, error) {
+	if field.JSON {
+		return sqlscan.EncodeJSON(field.Name, value.Interface())
+	}
+	return value.Interface(), nil
+}
+
+// skipValue applies the omitempty and omitzero tag options.
+func skipValue(opts sqlscan.TagOptions, value reflect.Value) bool {
+	return opts.OmitEmpty && isEmptyValue(value) || opts.OmitZero && isZeroValue(value)
+}
+
+// ExtractFieldsForInsert adds a column value for every `db` tagged field of
+// data, a pointer to a struct. Nil pointer fields are left out so that the
+// column default applies. Tag options decide which other fields are skipped
+// and how values are encoded:
+//
+//	ID        int64     `db:"id,autoincrement"`    // skipped when 0
+//	CreatedAt time.Time `db:"created_at,readonly"` // never inserted
+//	Status    string    `db:"status,default"`      // skipped when "", keeping the column default
+//	Note      string    `db:"note,omitempty"`      // skipped when ""
+//	Rubric    []Rule    `db:"rubric,json"`         // inserted as a JSON document
+//
+// Zero values such as false or 0 are inserted. Fields named id, created_at
+// or updated_at without tag options are never inserted, as before tag
+// options existed; give them an option to opt in.
+func (qb *QueryBuilder) ExtractFieldsForInsert(data interface{}) error {
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("data must be a pointer to a struct")
+	}
+	val = val.Elem()
+
+	for _, field := range sqlscan.TaggedFields(val.Type()) {
+		if field.ReadOnly || isLegacySkip(field) {
+			continue
+		}
+		value, ok := fieldValue(val, field)
+		if !ok || skipValue(field.TagOptions, value) {
+			continue
+		}
+		if (field.AutoIncrement || field.Default) && isZeroValue(value) {
+			continue
+		}
+		arg, err := columnValue(field, value)
+		if err != nil {
+			return err
+		}
+		qb.AddColumnValue(fieldColumn(field), arg)
+	}
+	return nil
+}
+
+// legacyInsertSkips are the columns ExtractFieldsForInsert has always left
+// to the database. ExtractFieldsForUpdate leaves them out as well.
+var legacyInsertSkips = map[string]struct{}{
+	"id":         {},
+	"created_at": {},
+	"updated_at": {},
+}
+
+// isLegacySkip reports whether field is one of legacyInsertSkips and has no
+// tag options that say how to insert it.
+func isLegacySkip(field sqlscan.Field) bool {
+	_, ok := legacyInsertSkips[field.Name]
+	return ok && field.TagOptions == (sqlscan.TagOptions{})
+}
+
+func columnSet(columns []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		set[column] = struct{}{}
+	}
+	return set
+}
+
+// isEmptyValue follows encoding/json's omitempty rules.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZeroValue uses the value's IsZero method when it has one, so that types
+// like time.Time are zero by their own definition.
+func isZeroValue(v reflect.Value) bool {
+	if zeroer, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return zeroer.IsZero()
+	}
+	return v.IsZero()
+}
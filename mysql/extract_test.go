@@ -0,0 +1,201 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExtractFieldsForInsert(t *testing.T) {
+	type legacy struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		Active    bool      `db:"is_active"`
+		Status    string    `db:"status"`
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	type tagged struct {
+		ID        int64     `db:"id,autoincrement"`
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at,insertonly"`
+		UpdatedAt time.Time `db:"updated_at,readonly"`
+	}
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		data    interface{}
+		columns []string
+		values  []interface{}
+	}{
+		{
+			name:    "untagged timestamps and id are left to the database",
+			data:    &legacy{ID: 7, Name: "a", CreatedAt: created},
+			columns: []string{"name", "is_active", "status"},
+			values:  []interface{}{"a", false, ""},
+		},
+		{
+			name:    "tag options opt in",
+			data:    &tagged{ID: 7, Name: "a", CreatedAt: created},
+			columns: []string{"id", "name", "created_at"},
+			values:  []interface{}{int64(7), "a", created},
+		},
+	}
+
+	for _, tt := range tests {
+		qb := NewQueryBuilder()
+		if err := qb.ExtractFieldsForInsert(tt.data); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(qb.columns, tt.columns) || !reflect.DeepEqual(qb.values, tt.values) {
+			t.Errorf("%s: got %v %v, want %v %v", tt.name, qb.columns, qb.values, tt.columns, tt.values)
+		}
+	}
+}
+
+func TestExtractRowsForInsertMatchesSingleInsert(t *testing.T) {
+	type legacy struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		Score     int       `db:"score"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	type tagged struct {
+		ID        int64     `db:"id,insertonly"`
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at,readonly"`
+	}
+
+	tests := []struct {
+		name string
+		rows interface{}
+		row  interface{}
+	}{
+		{name: "legacy", rows: []legacy{{Name: "a"}}, row: &legacy{Name: "a"}},
+		{name: "tagged", rows: []*tagged{{ID: 9, Name: "a"}}, row: &tagged{ID: 9, Name: "a"}},
+	}
+
+	for _, tt := range tests {
+		single := NewQueryBuilder()
+		if err := single.ExtractFieldsForInsert(tt.row); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		batch := NewQueryBuilder()
+		if err := batch.ExtractRowsForInsert(tt.rows); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(batch.columns, single.columns) {
+			t.Errorf("%s: batch columns = %v, single columns = %v", tt.name, batch.columns, single.columns)
+		}
+		if !reflect.DeepEqual(batch.rows, [][]interface{}{single.values}) {
+			t.Errorf("%s: batch rows = %v, single values = %v", tt.name, batch.rows, single.values)
+		}
+	}
+}
+
+func TestExtractDottedColumns(t *testing.T) {
+	type guardian struct {
+		Name string `db:"name"`
+	}
+	type student struct {
+		Name     string   `db:"name"`
+		Guardian guardian `db:"g,dotted"`
+	}
+
+	qb := NewQueryBuilder()
+	if err := qb.ExtractFieldsForInsert(&student{Name: "a", Guardian: guardian{Name: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	query, _ := qb.BuildInsertQuery("students")
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO `students` (`name`, `g.name`) VALUES (?, ?)"; query != want {
+		t.Errorf("insert = %q, want %q", query, want)
+	}
+
+	qb = NewQueryBuilder().Where(Eq("id", 1))
+	if err := qb.ExtractFieldsForUpdate(&student{Name: "a", Guardian: guardian{Name: "b"}}, UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	query, _ = qb.BuildUpdateQuery("students")
+	if want := "UPDATE `students` SET `name` = ?, `g.name` = ? WHERE `id` = ?"; query != want {
+		t.Errorf("update = %q, want %q", query, want)
+	}
+
+	batch := NewQueryBuilder()
+	if err := batch.ExtractRowsForInsert([]student{{Name: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	batches, err := batch.BuildInsertManyQueries("students")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO `students` (`name`, `g.name`) VALUES (?, ?)"; batches[0].Query != want {
+		t.Errorf("batch insert = %q, want %q", batches[0].Query, want)
+	}
+}
+
+func TestExtractFieldsForUpdate(t *testing.T) {
+	type legacy struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	type tagged struct {
+		ID        int64     `db:"id,autoincrement"`
+		Name      *string   `db:"name"`
+		Note      string    `db:"note,omitempty"`
+		CreatedBy int64     `db:"created_by,insertonly"`
+		Status    string    `db:"status,readonly"`
+		UpdatedAt time.Time `db:"updated_at,omitzero"`
+	}
+	updated := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	name := "b"
+
+	tests := []struct {
+		name    string
+		data    interface{}
+		opts    UpdateOptions
+		columns []string
+		args    []interface{}
+	}{
+		{
+			name:    "untagged id and timestamps are not set",
+			data:    &legacy{ID: 7, Name: "a", CreatedAt: updated, UpdatedAt: updated},
+			columns: []string{"name"},
+			args:    []interface{}{"a"},
+		},
+		{
+			name:    "tag options",
+			data:    &tagged{ID: 7, CreatedBy: 3, Status: "x", UpdatedAt: updated},
+			columns: []string{"updated_at"},
+			args:    []interface{}{updated},
+		},
+		{
+			name:    "pointer and omitempty fields",
+			data:    &tagged{Name: &name, Note: "n"},
+			columns: []string{"name", "note"},
+			args:    []interface{}{"b", "n"},
+		},
+		{
+			name:    "columns and skip",
+			data:    &tagged{Name: &name, Note: "n", UpdatedAt: updated},
+			opts:    UpdateOptions{Columns: []string{"name", "note"}, Skip: []string{"note"}},
+			columns: []string{"name"},
+			args:    []interface{}{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		qb := NewQueryBuilder()
+		if err := qb.ExtractFieldsForUpdate(tt.data, tt.opts); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(qb.setColumns, tt.columns) || !reflect.DeepEqual(qb.setArgs, tt.args) {
+			t.Errorf("%s: got %v %v, want %v %v", tt.name, qb.setColumns, qb.setArgs, tt.columns, tt.args)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidIdentifier is returned when a table or column name is not a
+	// legal `schema.table.column` identifier.
+	ErrInvalidIdentifier = errors.New("invalid identifier")
+
+	// ErrIdentifierNotAllowed is returned by the validator created with
+	// AllowIdentifiers when a name is not part of the allowlist.
+	ErrIdentifierNotAllowed = errors.New("identifier not allowed")
+)
+
+const maxIdentifierLength = 64
+
+// invalidIdentifier is rendered in place of a rejected identifier. An empty
+// quoted name is never a valid MySQL identifier, so a query built from it
+// cannot run even if its error is ignored.
+const invalidIdentifier = "``"
+
+var (
+	bareIdentifier = regexp.MustCompile(`^[A-Za-z0-9_$]+$`)
+	allDigits      = regexp.MustCompile(`^[0-9]+$`)
+	aliasPattern   = regexp.MustCompile(`^(.+?)\s+(?:(?i)AS\s+)?([^\s]+)$`)
+)
+
+// IdentifierValidator decides whether a column name may be used in a query.
+// It receives the unquoted, dot separated name, e.g. "courses.title".
+type IdentifierValidator func(name string) error
+
+// AllowIdentifiers returns a validator that only accepts the given names.
+// Names are compared case-insensitively, as MySQL does for columns.
+func AllowIdentifiers(names ...string) IdentifierValidator {
+	allowed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		parts, err := parseIdentifier(name, false)
+		if err != nil {
+			continue
+		}
+		allowed[strings.ToLower(strings.Join(parts, "."))] = struct{}{}
+	}
+
+	return func(name string) error {
+		if _, ok := allowed[strings.ToLower(name)]; !ok {
+			return fmt.Errorf("%q: %w", name, ErrIdentifierNotAllowed)
+		}
+		return nil
+	}
+}
+
+// ValidateIdentifier reports whether name is a legal, optionally qualified,
+// identifier. Parts may be bare (`[A-Za-z0-9_$]`) or backtick-quoted.
+func ValidateIdentifier(name string) error {
+	_, err := parseIdentifier(name, false)
+	return err
+}
+
+// QuoteIdentifier validates name and returns it with every part
+// backtick-quoted, e.g. "school.courses" becomes "`school`.`courses`".
+func QuoteIdentifier(name string) (string, error) {
+	parts, err := parseIdentifier(name, false)
+	if err != nil {
+		return "", err
+	}
+	return quoteParts(parts), nil
+}
+
+func quoteParts(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "*" {
+			quoted[i] = part
+			continue
+		}
+		quoted[i] = "`" + strings.ReplaceAll(part, "`", "``") + "`"
+	}
+	return strings.Join(quoted, ".")
+}
+
+// parseIdentifier splits a dotted identifier into its unquoted parts. When
+// allowStar is set the last part may be `*`, as in `courses.*`.
+func parseIdentifier(name string, allowStar bool) ([]string, error) {
+	invalid := fmt.Errorf("%q: %w", name, ErrInvalidIdentifier)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalid
+	}
+
+	parts := make([]string, 0, 3)
+	for i := 0; i <= len(name); {
+		var part string
+		if i < len(name) && name[i] == '`' {
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(name); j++ {
+				if name[j] != '`' {
+					sb.WriteByte(name[j])
+					continue
+				}
+				if j+1 < len(name) && name[j+1] == '`' {
+					sb.WriteByte('`')
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(name) || sb.Len() == 0 {
+				return nil, invalid
+			}
+			part = sb.String()
+			i = j + 1
+		} else {
+			end := strings.IndexByte(name[i:], '.')
+			if end < 0 {
+				end = len(name) - i
+			}
+			part = name[i : i+end]
+			i += end
+			isStar := allowStar && part == "*" && i == len(name)
+			if !isStar && (!bareIdentifier.MatchString(part) || allDigits.MatchString(part)) {
+				return nil, invalid
+			}
+		}
+
+		if len(part) > maxIdentifierLength || strings.HasSuffix(part, " ") {
+			return nil, invalid
+		}
+		parts = append(parts, part)
+
+		if i == len(name) {
+			break
+		}
+		if name[i] != '.' {
+			return nil, invalid
+		}
+		i++
+		if i == len(name) {
+			return nil, invalid
+		}
+	}
+
+	if len(parts) > 3 {
+		return nil, invalid
+	}
+	return parts, nil
+}
+
+// splitAlias separates an optional alias from a name, accepting both the
+// "name alias" and "name AS alias" forms.
+func splitAlias(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if _, err := parseIdentifier(name, true); err == nil {
+		return name, ""
+	}
+	if m := aliasPattern.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1]), m[2]
+	}
+	return name, ""
+}
+
+// quoteColumn quotes a column reference and runs it through the builder's
+// validator. Errors are recorded on the builder and invalidIdentifier is
+// rendered in place of the name, so the input never reaches the SQL.
+func (b *QueryBuilder) quoteColumn(name string) string {
+	parts, err := parseIdentifier(name, false)
+	if err == nil && b.validator != nil && !(len(parts) == 1 && b.isAggregateAlias(parts[0])) {
+		err = b.validator(strings.Join(parts, "."))
+	}
+	if err != nil {
+		b.setErr(err)
+		return invalidIdentifier
+	}
+	return quoteParts(parts)
+}
+
+// quoteSelectColumn quotes a projection entry, keeping an optional alias.
+// Entries that are not identifiers are rejected like any other column;
+// expressions go through SelectRaw.
+func (b *QueryBuilder) quoteSelectColumn(column string) string {
+	if strings.TrimSpace(column) == "*" {
+		return "*"
+	}
+
+	name, alias := splitAlias(column)
+	parts, err := parseIdentifier(name, true)
+	var aliasParts []string
+	if err == nil && alias != "" {
+		aliasParts, err = parseIdentifier(alias, false)
+		if err == nil && len(aliasParts) != 1 {
+			err = fmt.Errorf("%q: %w", alias, ErrInvalidIdentifier)
+		}
+	}
+	if err != nil {
+		b.setErr(err)
+		return invalidIdentifier
+	}
+
+	if b.validator != nil && parts[len(parts)-1] != "*" {
+		if err := b.validator(strings.Join(parts, ".")); err != nil {
+			b.setErr(err)
+			return invalidIdentifier
+		}
+	}
+
+	if alias == "" {
+		return quoteParts(parts)
+	}
+	return fmt.Sprintf("%s AS %s", quoteParts(parts), quoteParts(aliasParts))
+}
+
+// quoteTable quotes a table reference with an optional alias. Tables are not
+// subject to the column validator.
+func (b *QueryBuilder) quoteTable(table string) string {
+	name, alias := splitAlias(table)
+	parts, err := parseIdentifier(name, false)
+	if err != nil {
+		b.setErr(err)
+		return invalidIdentifier
+	}
+	if alias == "" {
+		return quoteParts(parts)
+	}
+
+	aliasParts, err := parseIdentifier(alias, false)
+	if err != nil || len(aliasParts) != 1 {
+		b.setErr(fmt.Errorf("%q: %w", alias, ErrInvalidIdentifier))
+		return invalidIdentifier
+	}
+	return fmt.Sprintf("%s AS %s", quoteParts(parts), quoteParts(aliasParts))
+}
@@ -0,0 +1,196 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowStar bool
+		want      []string
+	}{
+		{name: "title", want: []string{"title"}},
+		{name: " courses.title ", want: []string{"courses", "title"}},
+		{name: "school.courses.title", want: []string{"school", "courses", "title"}},
+		{name: "`order`", want: []string{"order"}},
+		{name: "`my table`.`a``b`", want: []string{"my table", "a`b"}},
+		{name: "`1`", want: []string{"1"}},
+		{name: "$col_1", want: []string{"$col_1"}},
+		{name: "courses.*", allowStar: true, want: []string{"courses", "*"}},
+		{name: "courses.*"},
+		{name: "*.title", allowStar: true},
+		{name: ""},
+		{name: "123"},
+		{name: "a.b.c.d"},
+		{name: "a..b"},
+		{name: "a."},
+		{name: ".a"},
+		{name: "``"},
+		{name: "`a"},
+		{name: "`a`b"},
+		{name: "`a `"},
+		{name: "title; DROP TABLE courses"},
+		{name: "title) OR (1=1"},
+		{name: "title -- x"},
+		{name: "a\x00b"},
+		{name: "a2345678901234567890123456789012345678901234567890123456789012345"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseIdentifier(tt.name, tt.allowStar)
+		if tt.want == nil {
+			if !errors.Is(err, ErrInvalidIdentifier) {
+				t.Errorf("%q: err = %v, want ErrInvalidIdentifier", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: parts = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	got, err := QuoteIdentifier("school.`a``b`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`school`.`a``b`"; got != want {
+		t.Errorf("QuoteIdentifier = %q, want %q", got, want)
+	}
+	if _, err := QuoteIdentifier("a b"); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("QuoteIdentifier(%q): err = %v, want ErrInvalidIdentifier", "a b", err)
+	}
+}
+
+func TestAllowIdentifiers(t *testing.T) {
+	validate := AllowIdentifiers("Title", "courses.id", "not valid")
+	for _, name := range []string{"title", "TITLE", "courses.id"} {
+		if err := validate(name); err != nil {
+			t.Errorf("%q: unexpected error %v", name, err)
+		}
+	}
+	for _, name := range []string{"id", "not valid", "title.x"} {
+		if err := validate(name); !errors.Is(err, ErrIdentifierNotAllowed) {
+			t.Errorf("%q: err = %v, want ErrIdentifierNotAllowed", name, err)
+		}
+	}
+}
+
+func TestSplitAlias(t *testing.T) {
+	tests := []struct {
+		in, name, alias string
+	}{
+		{in: "courses", name: "courses"},
+		{in: "courses c", name: "courses", alias: "c"},
+		{in: "courses AS c", name: "courses", alias: "c"},
+		{in: "courses as c", name: "courses", alias: "c"},
+		{in: "courses.*", name: "courses.*"},
+	}
+	for _, tt := range tests {
+		name, alias := splitAlias(tt.in)
+		if name != tt.name || alias != tt.alias {
+			t.Errorf("%q: got (%q, %q), want (%q, %q)", tt.in, name, alias, tt.name, tt.alias)
+		}
+	}
+}
+
+func TestBuildFailsClosed(t *testing.T) {
+	const evil = "courses; DROP TABLE users"
+	tests := []struct {
+		name  string
+		build func() (*QueryBuilder, string)
+		err   error
+	}{
+		{
+			name: "select table",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder()
+				query, _ := qb.BuildSelectManyQuery(evil)
+				return qb, query
+			},
+			err: ErrInvalidIdentifier,
+		},
+		{
+			name: "select column",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder().AllowColumns("id").Select([]string{"id", "(SELECT password FROM users)"})
+				query, _ := qb.BuildSelectQuery("courses")
+				return qb, query
+			},
+			err: ErrInvalidIdentifier,
+		},
+		{
+			name: "select column without validator",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder().Select([]string{"id; DROP TABLE x"})
+				query, _ := qb.BuildSelectManyQuery("courses")
+				return qb, query
+			},
+			err: ErrInvalidIdentifier,
+		},
+		{
+			name: "column not allowed",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder().AllowColumns("id").Where(Eq("secret", 1))
+				query, _ := qb.BuildCountQuery("courses")
+				return qb, query
+			},
+			err: ErrIdentifierNotAllowed,
+		},
+		{
+			name: "update column",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder().Set("title = 'x', role", "admin").Where(Eq("id", 1))
+				query, _ := qb.BuildUpdateQuery("courses")
+				return qb, query
+			},
+			err: ErrInvalidIdentifier,
+		},
+		{
+			name: "delete table",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder().Where(Eq("id", 1))
+				query, _ := qb.BuildDeleteQuery(evil)
+				return qb, query
+			},
+			err: ErrInvalidIdentifier,
+		},
+		{
+			name: "insert without values",
+			build: func() (*QueryBuilder, string) {
+				qb := NewQueryBuilder()
+				query, _ := qb.BuildInsertQuery("courses")
+				return qb, query
+			},
+			err: ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		qb, query := tt.build()
+		if !errors.Is(qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, qb.Err(), tt.err)
+		}
+		if query != "" {
+			t.Errorf("%s: query = %q, want empty", tt.name, query)
+		}
+	}
+}
+
+func TestQuoteTableRendersNoInput(t *testing.T) {
+	qb := NewQueryBuilder()
+	if got := qb.quoteTable("courses; DROP TABLE users"); got != invalidIdentifier {
+		t.Errorf("quoteTable = %q, want %q", got, invalidIdentifier)
+	}
+	if got := qb.quoteColumn("id) OR (1=1"); got != invalidIdentifier {
+		t.Errorf("quoteColumn = %q, want %q", got, invalidIdentifier)
+	}
+}
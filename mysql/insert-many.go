@@ -0,0 +1,224 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
+)
+
+const (
+	// MaxPlaceholders is the number of placeholders MySQL accepts in a
+	// single prepared statement.
+	MaxPlaceholders = 65535
+
+	// DefaultMaxPacketSize keeps a batch below the smallest default
+	// max_allowed_packet of supported MySQL versions (4MB).
+	DefaultMaxPacketSize = 4 << 20
+)
+
+var (
+	// ErrNoRows is returned when a batch insert has no rows to insert.
+	ErrNoRows = errors.New("no rows to insert")
+
+	// ErrRowTooLarge is returned when a single row does not fit in the
+	// configured packet size.
+	ErrRowTooLarge = errors.New("row exceeds max packet size")
+)
+
+// InsertBatch is one multi-row INSERT statement of a batch insert.
+type InsertBatch struct {
+	Query string
+	Args  []interface{}
+	Rows  int
+}
+
+// InsertColumns sets the columns of the rows added with InsertRow.
+func (b *QueryBuilder) InsertColumns(columns ...string) *QueryBuilder {
+	b.columns = columns
+	return b
+}
+
+// InsertRow adds a row of values, in InsertColumns order, to a batch insert.
+func (b *QueryBuilder) InsertRow(values ...interface{}) *QueryBuilder {
+	if len(values) != len(b.columns) {
+		b.setErr(fmt.Errorf("insert row has %d values for %d columns", len(values), len(b.columns)))
+		return b
+	}
+	b.rows = append(b.rows, values)
+	return b
+}
+
+// MaxPacketSize sets the approximate size, in bytes, that a single batch
+// statement may not exceed. It should not be larger than the server's
+// max_allowed_packet.
+func (b *QueryBuilder) MaxPacketSize(size int) *QueryBuilder {
+	b.maxPacketSize = size
+	return b
+}
+
+// BatchSize caps the number of rows per INSERT statement.
+func (b *QueryBuilder) BatchSize(rows int) *QueryBuilder {
+	b.batchSize = rows
+	return b
+}
+
+// ExtractRowsForInsert adds one row per element of data, a slice (or slice
+// pointer) of structs or struct pointers. The columns are taken from the
+// element type so that every row has the same shape: readonly fields are
+// left out, autoincrement, default, omitempty and omitzero fields are left
+// out when they are zero in every row, and nil pointers are inserted as NULL.
+// Untagged id, created_at and updated_at fields are left out as in
+// ExtractFieldsForInsert.
+func (qb *QueryBuilder) ExtractRowsForInsert(data interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(data))
+	if val.Kind() != reflect.Slice {
+		return fmt.Errorf("data must be a slice of structs")
+	}
+
+	elemType := val.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("data must be a slice of structs")
+	}
+
+	elems := make([]reflect.Value, val.Len())
+	for i := range elems {
+		elem := val.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return fmt.Errorf("data[%d] is nil", i)
+			}
+			elem = elem.Elem()
+		}
+		elems[i] = elem
+	}
+
+	var columns []string
+	var fields []sqlscan.Field
+	for _, field := range sqlscan.TaggedFields(elemType) {
+		if field.ReadOnly || isLegacySkip(field) {
+			continue
+		}
+		if field.AutoIncrement || field.Default || field.OmitEmpty || field.OmitZero {
+			if allZero(elems, field) {
+				continue
+			}
+		}
+		columns = append(columns, fieldColumn(field))
+		fields = append(fields, field)
+	}
+	qb.InsertColumns(columns...)
+
+	for i, elem := range elems {
+		row := make([]interface{}, len(fields))
+		for j, field := range fields {
+			value, ok := fieldValue(elem, field)
+			if !ok {
+				continue
+			}
+			arg, err := columnValue(field, value)
+			if err != nil {
+				return fmt.Errorf("data[%d]: %w", i, err)
+			}
+			row[j] = arg
+		}
+		qb.InsertRow(row...)
+	}
+	return nil
+}
+
+// allZero reports whether field is nil or zero in every element.
+func allZero(elems []reflect.Value, field sqlscan.Field) bool {
+	for _, elem := range elems {
+		if value, ok := fieldValue(elem, field); ok && !isZeroValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildInsertManyQueries splits the rows added with InsertRow into multi-row
+// INSERT statements that stay within MaxPlaceholders, the packet size and
+// the batch size.
+func (b *QueryBuilder) BuildInsertManyQueries(tableName string) ([]InsertBatch, error) {
+	if len(b.rows) == 0 || len(b.columns) == 0 {
+		return nil, ErrNoRows
+	}
+
+	table := b.quoteTable(b.tableName(tableName))
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		columns[i] = b.quoteColumn(column)
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	tuple := "(" + strings.Repeat("?, ", len(columns)-1) + "?)"
+
+	maxPacket := b.maxPacketSize
+	if maxPacket <= 0 {
+		maxPacket = DefaultMaxPacketSize
+	}
+	maxRows := MaxPlaceholders / len(columns)
+	if b.batchSize > 0 && b.batchSize < maxRows {
+		maxRows = b.batchSize
+	}
+
+	var batches []InsertBatch
+	var current InsertBatch
+	size := len(prefix)
+
+	flush := func() {
+		if current.Rows == 0 {
+			return
+		}
+		current.Query = prefix + strings.TrimSuffix(strings.Repeat(tuple+", ", current.Rows), ", ")
+		batches = append(batches, current)
+		current = InsertBatch{}
+		size = len(prefix)
+	}
+
+	for i, row := range b.rows {
+		rowSize := len(tuple) + 2
+		for _, value := range row {
+			rowSize += estimateArgSize(value)
+		}
+		if len(prefix)+rowSize > maxPacket {
+			return nil, fmt.Errorf("row %d: %w", i, ErrRowTooLarge)
+		}
+		if current.Rows == maxRows || size+rowSize > maxPacket {
+			flush()
+		}
+		current.Args = append(current.Args, row...)
+		current.Rows++
+		size += rowSize
+	}
+	flush()
+
+	return batches, nil
+}
+
+// estimateArgSize approximates the bytes a value takes on the wire.
+func estimateArgSize(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 1
+	case string:
+		return len(v) + 9
+	case []byte:
+		return len(v) + 9
+	case time.Time:
+		return 12
+	case fmt.Stringer:
+		return len(v.String()) + 9
+	}
+	return 9
+}
@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildInsertManyQueries(t *testing.T) {
+	rows := func(n int) *QueryBuilder {
+		qb := NewQueryBuilder().InsertColumns("course_id", "student_id")
+		for i := 0; i < n; i++ {
+			qb.InsertRow(7, i)
+		}
+		return qb
+	}
+	const prefix = "INSERT INTO `enrollments` (`course_id`, `student_id`) VALUES "
+	// Each row of two integers is estimated at 26 bytes.
+	const rowSize = 26
+
+	tests := []struct {
+		name  string
+		qb    *QueryBuilder
+		sizes []int
+		err   error
+	}{
+		{name: "single batch", qb: rows(3), sizes: []int{3}},
+		{name: "batch size", qb: rows(5).BatchSize(2), sizes: []int{2, 2, 1}},
+		{name: "placeholder limit", qb: rows(MaxPlaceholders/2 + 1), sizes: []int{MaxPlaceholders / 2, 1}},
+		{name: "packet size", qb: rows(5).MaxPacketSize(len(prefix) + 2*rowSize), sizes: []int{2, 2, 1}},
+		{name: "smallest limit wins", qb: rows(5).MaxPacketSize(len(prefix) + 3*rowSize).BatchSize(2), sizes: []int{2, 2, 1}},
+		{name: "row too large", qb: rows(1).MaxPacketSize(len(prefix) + rowSize - 1), err: ErrRowTooLarge},
+		{name: "no rows", qb: rows(0), err: ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		batches, err := tt.qb.BuildInsertManyQueries("enrollments")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+
+		var sizes []int
+		next := 0
+		for _, batch := range batches {
+			sizes = append(sizes, batch.Rows)
+			if want := prefix + strings.TrimSuffix(strings.Repeat("(?, ?), ", batch.Rows), ", "); batch.Query != want {
+				t.Errorf("%s: query = %.80q..., want %.80q...", tt.name, batch.Query, want)
+			}
+			want := make([]interface{}, 0, 2*batch.Rows)
+			for i := 0; i < batch.Rows; i++ {
+				want = append(want, 7, next)
+				next++
+			}
+			if !reflect.DeepEqual(batch.Args, want) {
+				t.Errorf("%s: args = %v, want %v", tt.name, batch.Args, want)
+			}
+		}
+		if !reflect.DeepEqual(sizes, tt.sizes) {
+			t.Errorf("%s: batch rows = %v, want %v", tt.name, sizes, tt.sizes)
+		}
+	}
+
+	if _, err := rows(1).InsertRow(1).BuildInsertManyQueries("enrollments"); err == nil {
+		t.Error("row length mismatch: err = nil")
+	}
+}
@@ -3,16 +3,22 @@ package mysql
 import (
 	"context"
 	"database/sql"
+
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
 )
 
 type IRepository interface {
 	FindOne(context.Context, string, *QueryBuilder, interface{}) error
-	FindMany(context.Context, string, *QueryBuilder, interface{}) (int, error)
+	FindMany(context.Context, string, *QueryBuilder, interface{}) (pagination.PageInfo, error)
 	UpdateOne(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
 	UpdateMany(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
 	InsertOne(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
+	InsertMany(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
+	UpsertOne(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
+	UpsertMany(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
 	DeleteOne(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
 	DeleteMany(context.Context, string, *QueryBuilder) (map[string]interface{}, error)
+	Call(ctx context.Context, proc string, args []interface{}, dests ...interface{}) error
 
 	BeginTransaction(ctx context.Context) (IUnitOfWork, error)
 }
@@ -27,4 +33,5 @@ type queryExecutor interface {
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"fmt"
+
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
+	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
+)
+
+// Keyset switches the builder to keyset pagination driven by its sort keys.
+// token is a cursor returned in PageInfo by a previous FindMany, or "" for
+// the first page. The sort keys must be plain columns that identify a row
+// uniquely, e.g. `created_at DESC, id DESC`, and must not be NULL.
+func (b *QueryBuilder) Keyset(codec *pagination.CursorCodec, token string, size int) *QueryBuilder {
+	keyset, err := pagination.NewKeyset(codec, token, int64(size))
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.keyset = keyset
+	return b
+}
+
+// seekCondition renders the keyset position as
+// `(k1 > ?) OR (k1 = ? AND k2 > ?) OR ...`, with `<` for descending keys
+// and every comparison inverted for backward pages.
+func (b *QueryBuilder) seekCondition() []conditionNode {
+	if err := b.keyset.CheckKeys(b.sortKeys()); err != nil {
+		b.setErr(err)
+		return nil
+	}
+	values := b.keyset.Cursor.Values
+	if len(values) == 0 {
+		return nil
+	}
+
+	branches := make([]Predicate, 0, len(b.orderBy))
+	for i, key := range b.orderBy {
+		if key.Nulls != NullsDefault {
+			b.setErr(fmt.Errorf("keyset pagination does not support NULLS ordering on %q", key.Column))
+			return nil
+		}
+
+		predicates := make([]Predicate, 0, i+1)
+		for j := 0; j < i; j++ {
+			predicates = append(predicates, comparison{column: b.orderBy[j].Column, op: "=", value: values[j]})
+		}
+
+		op := ">"
+		if (key.Direction == Desc) != b.keyset.Backward() {
+			op = "<"
+		}
+		predicates = append(predicates, comparison{column: key.Column, op: op, value: values[i]})
+		branches = append(branches, And(predicates...))
+	}
+
+	return []conditionNode{{
+		conjunction: "AND",
+		atomic:      true,
+		predicate:   Or(branches...),
+	}}
+}
+
+func (b *QueryBuilder) sortKeys() []pagination.SortKey {
+	keys := make([]pagination.SortKey, len(b.orderBy))
+	for i, key := range b.orderBy {
+		keys[i] = pagination.SortKey{Column: key.Column, Desc: key.Direction == Desc}
+	}
+	return keys
+}
+
+// orderKeys returns the sort keys, inverted when a backward keyset page is
+// requested.
+func (b *QueryBuilder) orderKeys() []OrderKey {
+	if b.keyset == nil || !b.keyset.Backward() {
+		return b.orderBy
+	}
+
+	keys := make([]OrderKey, len(b.orderBy))
+	for i, key := range b.orderBy {
+		keys[i] = key
+		if key.Direction == Desc {
+			keys[i].Direction = Asc
+		} else {
+			keys[i].Direction = Desc
+		}
+	}
+	return keys
+}
+
+// keysetValues extracts the sort key values of a scanned row, matching the
+// unqualified column name against the row's db tags and field names, or the
+// keys of a dynamic map row.
+func (b *QueryBuilder) keysetValues(item interface{}) ([]interface{}, error) {
+	columns := make([]string, len(b.orderBy))
+	for i, key := range b.orderBy {
+		parts, err := parseIdentifier(key.Column, false)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = parts[len(parts)-1]
+	}
+	if row, ok := item.(*map[string]interface{}); ok {
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			value, ok := (*row)[column]
+			if !ok {
+				return nil, fmt.Errorf("column %q: %w", column, sqlscan.ErrStructFieldMissing)
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	return sqlscan.Values(columns, item)
+}
@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
+)
+
+func TestKeysetSeekGroupsConditions(t *testing.T) {
+	codec := pagination.NewCursorCodec([]byte("secret"))
+	token, err := codec.Encode(pagination.Cursor{
+		Direction: pagination.Forward,
+		Keys:      []pagination.SortKey{{Column: "id"}},
+		Values:    []interface{}{int64(5)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+		args []interface{}
+	}{
+		{
+			name: "or conditions",
+			qb:   NewQueryBuilder().Where(Eq("course_id", 1)).OrWhere(Eq("course_id", 2)),
+			want: "SELECT * FROM `t` WHERE (`course_id` = ? OR `course_id` = ?) AND `id` > ? ORDER BY `id` ASC LIMIT 11",
+			args: []interface{}{1, 2, int64(5)},
+		},
+		{
+			name: "single condition",
+			qb:   NewQueryBuilder().Where(Eq("course_id", 1)),
+			want: "SELECT * FROM `t` WHERE `course_id` = ? AND `id` > ? ORDER BY `id` ASC LIMIT 11",
+			args: []interface{}{1, int64(5)},
+		},
+		{
+			name: "no conditions",
+			qb:   NewQueryBuilder(),
+			want: "SELECT * FROM `t` WHERE `id` > ? ORDER BY `id` ASC LIMIT 11",
+			args: []interface{}{int64(5)},
+		},
+	}
+
+	for _, tt := range tests {
+		qb := tt.qb.OrderByAsc("id").Keyset(codec, token, 10)
+		query, args := qb.BuildSelectManyQuery("t")
+		if err := qb.Err(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLockOutsideTransaction is returned when a locking read is run outside
+// a unit of work, where the lock would be released as soon as the statement
+// completes.
+var ErrLockOutsideTransaction = errors.New("locking read requires a transaction")
+
+type LockMode string
+
+const (
+	LockForUpdate LockMode = "FOR UPDATE"
+	LockForShare  LockMode = "FOR SHARE"
+)
+
+type lockOptions struct {
+	mode       LockMode
+	of         []string
+	skipLocked bool
+	noWait     bool
+}
+
+// ForUpdate locks the selected rows for writing: `SELECT ... FOR UPDATE`.
+func (b *QueryBuilder) ForUpdate() *QueryBuilder {
+	b.lock.mode = LockForUpdate
+	return b
+}
+
+// ForShare locks the selected rows for reading: `SELECT ... FOR SHARE`.
+func (b *QueryBuilder) ForShare() *QueryBuilder {
+	b.lock.mode = LockForShare
+	return b
+}
+
+// LockOf restricts the lock to the given tables (or aliases) of a join.
+func (b *QueryBuilder) LockOf(tables ...string) *QueryBuilder {
+	b.lock.of = append(b.lock.of, tables...)
+	return b
+}
+
+// SkipLocked skips rows locked by other transactions instead of waiting,
+// e.g. to let several workers pull jobs from a queue table.
+func (b *QueryBuilder) SkipLocked() *QueryBuilder {
+	b.lock.skipLocked = true
+	return b
+}
+
+// NoWait fails immediately instead of waiting for locked rows.
+func (b *QueryBuilder) NoWait() *QueryBuilder {
+	b.lock.noWait = true
+	return b
+}
+
+func (b *QueryBuilder) isLocking() bool {
+	return b.lock.mode != ""
+}
+
+func (b *QueryBuilder) buildLockClause() string {
+	if !b.isLocking() {
+		if b.lock.skipLocked || b.lock.noWait || len(b.lock.of) > 0 {
+			b.setErr(errors.New("SKIP LOCKED, NOWAIT and OF require ForUpdate or ForShare"))
+		}
+		return ""
+	}
+	if b.lock.skipLocked && b.lock.noWait {
+		b.setErr(errors.New("SKIP LOCKED and NOWAIT are mutually exclusive"))
+		return ""
+	}
+
+	clause := string(b.lock.mode)
+	if len(b.lock.of) > 0 {
+		tables := make([]string, len(b.lock.of))
+		for i, table := range b.lock.of {
+			parts, err := parseIdentifier(table, false)
+			if err != nil {
+				b.setErr(err)
+				return ""
+			}
+			tables[i] = quoteParts(parts)
+		}
+		clause += fmt.Sprintf(" OF %s", strings.Join(tables, ", "))
+	}
+	if b.lock.skipLocked {
+		clause += " SKIP LOCKED"
+	}
+	if b.lock.noWait {
+		clause += " NOWAIT"
+	}
+	return clause
+}
@@ -0,0 +1,205 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Predicate is a typed WHERE condition that generates its own placeholders
+// and args. Predicates are created with the helpers in this file and passed
+// to Where, OrWhere, Not or OrNot. Column names are quoted and validated
+// by the builder that renders them.
+type Predicate interface {
+	build(b *QueryBuilder) (string, []interface{})
+}
+
+type comparison struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+func (p comparison) build(b *QueryBuilder) (string, []interface{}) {
+	return fmt.Sprintf("%s %s ?", b.quoteColumn(p.column), p.op), []interface{}{p.value}
+}
+
+type between struct {
+	column string
+	not    bool
+	from   interface{}
+	to     interface{}
+}
+
+func (p between) build(b *QueryBuilder) (string, []interface{}) {
+	op := "BETWEEN"
+	if p.not {
+		op = "NOT BETWEEN"
+	}
+	return fmt.Sprintf("%s %s ? AND ?", b.quoteColumn(p.column), op), []interface{}{p.from, p.to}
+}
+
+type nullCheck struct {
+	column string
+	not    bool
+}
+
+func (p nullCheck) build(b *QueryBuilder) (string, []interface{}) {
+	if p.not {
+		return fmt.Sprintf("%s IS NOT NULL", b.quoteColumn(p.column)), nil
+	}
+	return fmt.Sprintf("%s IS NULL", b.quoteColumn(p.column)), nil
+}
+
+type inList struct {
+	column string
+	not    bool
+	values []interface{}
+}
+
+func (p inList) build(b *QueryBuilder) (string, []interface{}) {
+	if len(p.values) == 0 {
+		// an empty IN list is invalid SQL, so fall back to a constant
+		// expression with the same meaning
+		if p.not {
+			return "1 = 1", nil
+		}
+		return "1 = 0", nil
+	}
+
+	op := "IN"
+	if p.not {
+		op = "NOT IN"
+	}
+	placeholders := strings.Repeat("?,", len(p.values)-1) + "?"
+	return fmt.Sprintf("%s %s (%s)", b.quoteColumn(p.column), op, placeholders), p.values
+}
+
+type compound struct {
+	conjunction string
+	predicates  []Predicate
+}
+
+func (p compound) build(b *QueryBuilder) (string, []interface{}) {
+	parts := make([]string, 0, len(p.predicates))
+	var args []interface{}
+	for _, predicate := range p.predicates {
+		expr, predicateArgs := predicate.build(b)
+		parts = append(parts, expr)
+		args = append(args, predicateArgs...)
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return parts[0], args
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " "+p.conjunction+" ")), args
+}
+
+type rawPredicate struct {
+	expr string
+	args []interface{}
+}
+
+func (p rawPredicate) build(b *QueryBuilder) (string, []interface{}) {
+	return fmt.Sprintf("(%s)", p.expr), p.args
+}
+
+// Eq renders `column = ?`, or `column IS NULL` when value is nil or a nil
+// pointer, map, slice or interface.
+func Eq(column string, value interface{}) Predicate {
+	if isNil(value) {
+		return IsNull(column)
+	}
+	return comparison{column: column, op: "=", value: value}
+}
+
+// Ne renders `column <> ?`, or `column IS NOT NULL` when value is nil or a
+// nil pointer, map, slice or interface.
+func Ne(column string, value interface{}) Predicate {
+	if isNil(value) {
+		return IsNotNull(column)
+	}
+	return comparison{column: column, op: "<>", value: value}
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func Gt(column string, value interface{}) Predicate {
+	return comparison{column: column, op: ">", value: value}
+}
+
+func Gte(column string, value interface{}) Predicate {
+	return comparison{column: column, op: ">=", value: value}
+}
+
+func Lt(column string, value interface{}) Predicate {
+	return comparison{column: column, op: "<", value: value}
+}
+
+func Lte(column string, value interface{}) Predicate {
+	return comparison{column: column, op: "<=", value: value}
+}
+
+func Between(column string, from, to interface{}) Predicate {
+	return between{column: column, from: from, to: to}
+}
+
+func NotBetween(column string, from, to interface{}) Predicate {
+	return between{column: column, not: true, from: from, to: to}
+}
+
+func IsNull(column string) Predicate {
+	return nullCheck{column: column}
+}
+
+func IsNotNull(column string) Predicate {
+	return nullCheck{column: column, not: true}
+}
+
+// Like renders `column LIKE ?`. The pattern is passed as-is, so callers
+// decide where the % wildcards go.
+func Like(column string, pattern string) Predicate {
+	return comparison{column: column, op: "LIKE", value: pattern}
+}
+
+func NotLike(column string, pattern string) Predicate {
+	return comparison{column: column, op: "NOT LIKE", value: pattern}
+}
+
+// In renders `column IN (?, ...)`. An empty list never matches.
+func In(column string, values ...interface{}) Predicate {
+	return inList{column: column, values: values}
+}
+
+// NotIn renders `column NOT IN (?, ...)`. An empty list always matches.
+func NotIn(column string, values ...interface{}) Predicate {
+	return inList{column: column, not: true, values: values}
+}
+
+// And joins predicates with AND inside parentheses.
+func And(predicates ...Predicate) Predicate {
+	return compound{conjunction: "AND", predicates: predicates}
+}
+
+// Or joins predicates with OR inside parentheses.
+func Or(predicates ...Predicate) Predicate {
+	return compound{conjunction: "OR", predicates: predicates}
+}
+
+// Raw wraps a SQL fragment so it can be combined with typed predicates.
+func Raw(expr string, args ...interface{}) Predicate {
+	return rawPredicate{expr: expr, args: args}
+}
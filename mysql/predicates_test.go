@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEqNeNil(t *testing.T) {
+	var nilTime *time.Time
+	var nilStringer interface{ String() string } = nilTime
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		predicate Predicate
+		want      string
+		args      []interface{}
+	}{
+		{name: "eq nil", predicate: Eq("deleted_at", nil), want: "`deleted_at` IS NULL"},
+		{name: "eq nil pointer", predicate: Eq("deleted_at", nilTime), want: "`deleted_at` IS NULL"},
+		{name: "eq nil interface", predicate: Eq("deleted_at", nilStringer), want: "`deleted_at` IS NULL"},
+		{name: "eq value", predicate: Eq("deleted_at", &now), want: "`deleted_at` = ?", args: []interface{}{&now}},
+		{name: "ne nil", predicate: Ne("deleted_at", nil), want: "`deleted_at` IS NOT NULL"},
+		{name: "ne nil pointer", predicate: Ne("deleted_at", nilTime), want: "`deleted_at` IS NOT NULL"},
+		{name: "ne value", predicate: Ne("grade", 0), want: "`grade` <> ?", args: []interface{}{0}},
+	}
+	for _, tt := range tests {
+		qb := NewQueryBuilder().Where(tt.predicate)
+		query, args := qb.BuildSelectManyQuery("t")
+		if err := qb.Err(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if want := "SELECT * FROM `t` WHERE " + tt.want; query != want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, want)
+		}
+		if len(args) != len(tt.args) || len(args) > 0 && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
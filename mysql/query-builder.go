@@ -1,94 +1,362 @@
 package mysql
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
+	"math"
 	"strings"
+
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
+)
+
+var (
+	// ErrInvalidConjunction is returned when a condition is joined with
+	// anything but AND, OR or XOR.
+	ErrInvalidConjunction = errors.New("invalid conjunction")
+
+	// ErrInvalidJoinType is returned for a join type that is not one of
+	// INNER, CROSS, LEFT, RIGHT (optionally OUTER) or NATURAL.
+	ErrInvalidJoinType = errors.New("invalid join type")
+
+	// ErrNoConditions is returned when an UPDATE or DELETE has no WHERE
+	// conditions, which would affect the whole table.
+	ErrNoConditions = errors.New("statement has no conditions")
 )
 
 type JoinOptions struct {
-	JoinType string `json:"joinType,omitempty"`
-	On       string `json:"on,omitempty"`
-	Table    string `json:"table,omitempty"`
+	JoinType string        `json:"joinType,omitempty"`
+	On       string        `json:"on,omitempty"`
+	Table    string        `json:"table,omitempty"`
+	Alias    string        `json:"alias,omitempty"`
+	Args     []interface{} `json:"args,omitempty"`
+
+	subquery *QueryBuilder
+}
+
+var joinTypes = map[string]bool{
+	"":            true,
+	"INNER":       true,
+	"CROSS":       true,
+	"LEFT":        true,
+	"LEFT OUTER":  true,
+	"RIGHT":       true,
+	"RIGHT OUTER": true,
+	"NATURAL":     true,
+}
+
+// conditionNode is a single entry of the WHERE expression tree. A node is
+// either a leaf holding a SQL fragment and its args, or a group of nested
+// nodes rendered inside parentheses.
+type conditionNode struct {
+	conjunction string
+	negate      bool
+	atomic      bool
+	expr        string
+	args        []interface{}
+	predicate   Predicate
+	group       []conditionNode
+}
+
+// selectExpr is a raw projection expression added with SelectRaw.
+type selectExpr struct {
+	expr string
+	args []interface{}
 }
 
 type QueryBuilder struct {
-	columns      []string
-	values       []interface{}
-	setClauses   []string
-	conditions   []string
-	conjunctions []string
-	args         []interface{}
-	joins        []JoinOptions
-	limit        int
-	offset       int
-	orderBy      string
-	setArgs      []interface{}
+	columns       []string
+	selectExprs   []selectExpr
+	values        []interface{}
+	rows          [][]interface{}
+	setColumns    []string
+	conditions    []conditionNode
+	from          string
+	fromSubquery  *QueryBuilder
+	fromAlias     string
+	joins         []JoinOptions
+	distinct      bool
+	limit         int64
+	offset        int64
+	page          int64
+	orderBy       []OrderKey
+	groupBy       []string
+	having        []conditionNode
+	aggregates    []aggregate
+	setArgs       []interface{}
+	validator     IdentifierValidator
+	keyset        *pagination.Keyset
+	batchSize     int
+	maxPacketSize int
+
+	duplicateKeyUpdates []duplicateKeyUpdate
+	rowAlias            string
+	lock                lockOptions
+	ctes                []commonTableExpression
+	unions              []union
+	err                 error
 }
 
 func NewQueryBuilder() *QueryBuilder {
 	return &QueryBuilder{}
 }
 
+// Select sets the projected columns. Each entry must be a column reference,
+// optionally qualified and aliased ("c.title AS course"), or a "*" wildcard;
+// anything else is rejected. Use SelectRaw for expressions.
 func (b *QueryBuilder) Select(columns []string) *QueryBuilder {
 	b.columns = columns
 	return b
 }
 
-func (b *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
-	if len(b.conditions) > 0 {
-		b.conjunctions = append(b.conjunctions, "AND")
+// SelectRaw appends a raw SQL expression, e.g. "COALESCE(grade, 0) AS grade",
+// to the projection after the Select columns. The expression is used as is,
+// so it must never contain user input; pass values as args instead.
+func (b *QueryBuilder) SelectRaw(expr string, args ...interface{}) *QueryBuilder {
+	b.selectExprs = append(b.selectExprs, selectExpr{expr: expr, args: args})
+	return b
+}
+
+// ValidateColumns sets the validator applied to every column name used by
+// the builder, e.g. sort and search fields taken from query params.
+func (b *QueryBuilder) ValidateColumns(validator IdentifierValidator) *QueryBuilder {
+	b.validator = validator
+	return b
+}
+
+// AllowColumns restricts the column names used by the builder to columns.
+func (b *QueryBuilder) AllowColumns(columns ...string) *QueryBuilder {
+	return b.ValidateColumns(AllowIdentifiers(columns...))
+}
+
+// Where adds a condition joined with AND. The condition is either a raw SQL
+// fragment followed by its args, or one or more Predicates:
+//
+//	qb.Where("grade = ?", 5)
+//	qb.Where(mysql.Eq("grade", 5), mysql.IsNull("deleted_at"))
+func (b *QueryBuilder) Where(condition interface{}, args ...interface{}) *QueryBuilder {
+	return b.addCondition("AND", false, condition, args)
+}
+
+func (b *QueryBuilder) OrWhere(condition interface{}, args ...interface{}) *QueryBuilder {
+	return b.addCondition("OR", false, condition, args)
+}
+
+func (b *QueryBuilder) WhereWithConjunction(conjunction string, condition interface{}, args ...interface{}) *QueryBuilder {
+	conjunction, err := normalizeConjunction(conjunction)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	return b.addCondition(conjunction, false, condition, args)
+}
+
+// Not adds a negated condition joined with AND: `AND NOT (condition)`.
+func (b *QueryBuilder) Not(condition interface{}, args ...interface{}) *QueryBuilder {
+	return b.addCondition("AND", true, condition, args)
+}
+
+func (b *QueryBuilder) OrNot(condition interface{}, args ...interface{}) *QueryBuilder {
+	return b.addCondition("OR", true, condition, args)
+}
+
+func (b *QueryBuilder) addCondition(conjunction string, negate bool, condition interface{}, args []interface{}) *QueryBuilder {
+	if node, ok := b.newCondition(conjunction, negate, condition, args); ok {
+		b.conditions = append(b.conditions, node)
 	}
-	b.conditions = append(b.conditions, condition)
-	b.args = append(b.args, args...)
 	return b
 }
 
-func (b *QueryBuilder) WhereWithConjunction(conjunction, condition string, args ...interface{}) *QueryBuilder {
-	if len(b.conditions) > 0 {
-		b.conjunctions = append(b.conjunctions, conjunction)
+func (b *QueryBuilder) newCondition(conjunction string, negate bool, condition interface{}, args []interface{}) (conditionNode, bool) {
+	switch c := condition.(type) {
+	case string:
+		return conditionNode{
+			conjunction: conjunction,
+			negate:      negate,
+			expr:        c,
+			args:        args,
+		}, true
+	case Predicate:
+		predicates := []Predicate{c}
+		for _, arg := range args {
+			predicate, ok := arg.(Predicate)
+			if !ok {
+				b.setErr(fmt.Errorf("invalid where condition: expected mysql.Predicate, got %T", arg))
+				return conditionNode{}, false
+			}
+			predicates = append(predicates, predicate)
+		}
+		if len(predicates) > 1 {
+			c = And(predicates...)
+		}
+		return conditionNode{
+			conjunction: conjunction,
+			negate:      negate,
+			atomic:      true,
+			predicate:   c,
+		}, true
+	default:
+		b.setErr(fmt.Errorf("invalid where condition: unsupported type %T", condition))
+		return conditionNode{}, false
+	}
+}
+
+// WhereGroup adds a parenthesized group of conditions joined with AND. The
+// callback receives a fresh builder; only its conditions are used.
+func (b *QueryBuilder) WhereGroup(fn func(g *QueryBuilder)) *QueryBuilder {
+	return b.addGroup("AND", false, fn)
+}
+
+func (b *QueryBuilder) OrWhereGroup(fn func(g *QueryBuilder)) *QueryBuilder {
+	return b.addGroup("OR", false, fn)
+}
+
+func (b *QueryBuilder) WhereNotGroup(fn func(g *QueryBuilder)) *QueryBuilder {
+	return b.addGroup("AND", true, fn)
+}
+
+func (b *QueryBuilder) OrWhereNotGroup(fn func(g *QueryBuilder)) *QueryBuilder {
+	return b.addGroup("OR", true, fn)
+}
+
+func (b *QueryBuilder) addGroup(conjunction string, negate bool, fn func(g *QueryBuilder)) *QueryBuilder {
+	group := NewQueryBuilder()
+	fn(group)
+	if group.err != nil {
+		b.setErr(group.err)
+		return b
 	}
-	b.conditions = append(b.conditions, condition)
-	b.args = append(b.args, args...)
+	if len(group.conditions) == 0 {
+		return b
+	}
+	b.conditions = append(b.conditions, conditionNode{
+		conjunction: conjunction,
+		negate:      negate,
+		group:       group.conditions,
+	})
 	return b
 }
 
-func (b *QueryBuilder) Join(joinType, table, on string) *QueryBuilder {
-	b.joins = append(b.joins, JoinOptions{
+// Join adds a join. The table may carry an alias ("students s") and args
+// are bound to the placeholders of the ON condition.
+func (b *QueryBuilder) Join(joinType, table, on string, args ...interface{}) *QueryBuilder {
+	return b.JoinWith(JoinOptions{
 		JoinType: joinType,
 		Table:    table,
 		On:       on,
+		Args:     args,
 	})
+}
+
+func (b *QueryBuilder) JoinWith(join JoinOptions) *QueryBuilder {
+	b.joins = append(b.joins, join)
+	return b
+}
+
+func (b *QueryBuilder) InnerJoin(table, on string, args ...interface{}) *QueryBuilder {
+	return b.Join("INNER", table, on, args...)
+}
+
+func (b *QueryBuilder) LeftJoin(table, on string, args ...interface{}) *QueryBuilder {
+	return b.Join("LEFT", table, on, args...)
+}
+
+func (b *QueryBuilder) RightJoin(table, on string, args ...interface{}) *QueryBuilder {
+	return b.Join("RIGHT", table, on, args...)
+}
+
+// JoinSubquery joins a derived table: `JOIN (SELECT ...) AS alias ON on`.
+func (b *QueryBuilder) JoinSubquery(joinType string, sub *QueryBuilder, alias, on string, args ...interface{}) *QueryBuilder {
+	return b.JoinWith(JoinOptions{
+		JoinType: joinType,
+		Alias:    alias,
+		On:       on,
+		Args:     args,
+		subquery: sub,
+	})
+}
+
+// Distinct turns the query into SELECT DISTINCT. Count queries then count
+// distinct rows.
+func (b *QueryBuilder) Distinct() *QueryBuilder {
+	b.distinct = true
 	return b
 }
 
 func (b *QueryBuilder) Limit(limit int) *QueryBuilder {
-	b.limit = limit
+	b.limit = int64(limit)
 	return b
 }
 
+// Offset selects the 1-based page of Limit rows.
+//
+// Deprecated: despite its name Offset takes a page number, not a row count.
+// Use Page for page numbers or Skip for a number of rows.
 func (b *QueryBuilder) Offset(offset int) *QueryBuilder {
-	b.offset = offset
+	b.page = int64(offset)
+	b.offset = 0
 	return b
 }
 
-func (b *QueryBuilder) OrderBy(orderBy string) *QueryBuilder {
-	b.orderBy = orderBy
+// Skip sets the number of rows to skip.
+func (b *QueryBuilder) Skip(rows int) *QueryBuilder {
+	b.offset = int64(rows)
+	b.page = 0
+	return b
+}
+
+// Page selects the 1-based page number of the given size.
+func (b *QueryBuilder) Page(number, size int) *QueryBuilder {
+	return b.Paginate(pagination.Page{Number: int64(number), Size: int64(size)})
+}
+
+func (b *QueryBuilder) Paginate(p pagination.Paginator) *QueryBuilder {
+	b.offset, b.limit = p.Window()
+	b.page = 0
 	return b
 }
 
+// window returns the rows to skip and take, resolving a page set through the
+// deprecated Offset against the limit.
+func (b *QueryBuilder) window() (int64, int64) {
+	if b.page > 0 {
+		return (b.page - 1) * b.limit, b.limit
+	}
+	return b.offset, b.limit
+}
+
+// OrderBy replaces the sort with a comma separated `column [ASC|DESC]` list.
+// An empty string clears it. Use OrderByAsc, OrderByDesc, OrderByKeys or
+// SortBy to add keys to the current sort.
+func (b *QueryBuilder) OrderBy(orderBy string) *QueryBuilder {
+	b.orderBy = nil
+	if strings.TrimSpace(orderBy) == "" {
+		return b
+	}
+	keys, err := parseOrderBy(orderBy)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	return b.OrderByKeys(keys...)
+}
+
 func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuilder {
 	if len(values) == 0 {
 		return qb
 	}
-	placeholders := strings.Repeat("?,", len(values)-1) + "?"
-	qb.conditions = append(qb.conditions, fmt.Sprintf("%s IN (%s)", column, placeholders))
-	qb.args = append(qb.args, values...)
-	return qb
+	return qb.Where(In(column, values...))
+}
+
+// WhereNotIn is the negated form of WhereIn. An empty list is ignored.
+func (qb *QueryBuilder) WhereNotIn(column string, values []interface{}) *QueryBuilder {
+	if len(values) == 0 {
+		return qb
+	}
+	return qb.Where(NotIn(column, values...))
 }
 
 func (qb *QueryBuilder) Set(column string, value interface{}) *QueryBuilder {
-	qb.setClauses = append(qb.setClauses, fmt.Sprintf("%s = ?", column))
+	qb.setColumns = append(qb.setColumns, column)
 	qb.setArgs = append(qb.setArgs, value)
 
 	return qb
@@ -124,153 +392,343 @@ func (qb *QueryBuilder) Search(columns []string, searchText string) *QueryBuilde
 		return qb
 	}
 
-	conditionStrings := make([]string, 0, len(columns))
-	args := make([]interface{}, 0, len(columns))
-
+	group := make([]conditionNode, 0, len(columns))
 	for _, column := range columns {
-		conditionStrings = append(conditionStrings, fmt.Sprintf("%s LIKE ?", column))
-		args = append(args, "%"+searchText+"%")
+		group = append(group, conditionNode{
+			conjunction: "OR",
+			atomic:      true,
+			predicate:   Like(column, "%"+searchText+"%"),
+		})
 	}
 
-	if len(conditionStrings) > 0 {
-		qb.conditions = append(qb.conditions, strings.Join(conditionStrings, " OR "))
-		qb.args = append(qb.args, args...)
-	}
+	qb.conditions = append(qb.conditions, conditionNode{
+		conjunction: "AND",
+		group:       group,
+	})
 
 	return qb
 }
 
-func (b *QueryBuilder) BuildSelectQuery(tableName string) (string, []interface{}) {
-	columns := joinColumns(b.columns)
-	joinClause := buildJoinClause(b.joins)
-	whereClause := buildWhereClause(b.conditions)
+// Err returns the first error recorded while the query was being composed.
+// The repository checks it before running any query built from b.
+func (b *QueryBuilder) Err() error {
+	return b.err
+}
+
+func (b *QueryBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// failClosed returns query and args, or no SQL at all once an error has
+// been recorded, so that a rejected identifier never reaches the database.
+func (b *QueryBuilder) failClosed(query string, args []interface{}) (string, []interface{}) {
+	if b.err != nil {
+		return "", nil
+	}
+	return query, args
+}
 
-	query := fmt.Sprintf("SELECT %s FROM %s %s %s", columns, tableName, joinClause, whereClause)
-	return query, b.args
+// BuildSelectQuery builds the query for a single row. It keeps the sort keys
+// and limits the result to one row unless a limit is set, so that a locking
+// read only locks the row it returns.
+func (b *QueryBuilder) BuildSelectQuery(tableName string) (string, []interface{}) {
+	query, args := b.buildSelect(tableName, false)
+	return b.failClosed(joinClauses(query, b.buildLockClause()), args)
 }
 
 func (b *QueryBuilder) BuildSelectManyQuery(tableName string) (string, []interface{}) {
-	columns := joinColumns(b.columns)
-	joinClause := buildJoinClause(b.joins)
-	whereClause := buildWhereClause(b.conditions)
-	paginationClause := buildPaginationClause(b.limit, b.offset, b.orderBy)
+	query, args := b.buildSelect(tableName, true)
+	return b.failClosed(joinClauses(query, b.buildLockClause()), args)
+}
+
+// buildSelect renders the full SELECT statement: the WITH clause, the
+// select body and its UNION parts, and, when paginate is set, the ORDER BY
+// and LIMIT of the combined result.
+func (b *QueryBuilder) buildSelect(tableName string, paginate bool) (string, []interface{}) {
+	withClause, withArgs := b.buildWithClause()
+	query, args := b.buildSelectBody(tableName, paginate)
+	unionClause, unionArgs := b.buildUnionClause()
+
+	paginationClause := b.buildPaginationClause(!paginate)
 
-	query := fmt.Sprintf("SELECT %s FROM %s %s %s %s", columns, tableName, joinClause, whereClause, paginationClause)
-	return query, b.args
+	query = joinClauses(withClause, query, unionClause, paginationClause)
+	return query, mergeArgs(withArgs, args, unionArgs)
+}
+
+func (b *QueryBuilder) buildSelectBody(tableName string, paginate bool) (string, []interface{}) {
+	columns, columnArgs := b.buildColumns()
+	table, fromArgs := b.buildFrom(tableName)
+	joinClause, joinArgs := b.buildJoinClause()
+	var seek []conditionNode
+	if paginate && b.keyset != nil {
+		seek = b.seekCondition()
+	}
+	whereClause, whereArgs := b.buildWhereClause(seek...)
+	groupByClause := b.buildGroupByClause()
+	havingClause, havingArgs := b.buildHavingClause()
+
+	selectClause := "SELECT " + columns
+	if b.distinct {
+		selectClause = "SELECT DISTINCT " + columns
+	}
+
+	query := joinClauses(selectClause, "FROM "+table, joinClause, whereClause, groupByClause, havingClause)
+	return query, mergeArgs(columnArgs, fromArgs, joinArgs, whereArgs, havingArgs)
 }
 
 func (b *QueryBuilder) BuildUpdateQuery(tableName string) (string, []interface{}) {
-	setClause := strings.Join(b.setClauses, ", ")
-	whereClause := buildWhereClause(b.conditions)
+	if len(b.setColumns) == 0 {
+		b.setErr(ErrNoSetColumns)
+	}
+	table := b.quoteTable(b.tableName(tableName))
+	setClause := b.buildSetClause()
+	whereClause, whereArgs := b.buildWhereClause()
+	if whereClause == "" {
+		b.setErr(ErrNoConditions)
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s %s", tableName, setClause, whereClause)
-	return query, b.args
+	query := fmt.Sprintf("UPDATE %s SET %s %s", table, setClause, whereClause)
+	return b.failClosed(query, mergeArgs(b.setArgs, whereArgs))
 }
 
 func (qb *QueryBuilder) BuildUpdateManyQuery(tableName string) (string, []interface{}) {
-	setClause := strings.Join(qb.setClauses, ", ")
-	whereClause := strings.Join(qb.conditions, " AND ")
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", tableName, setClause, whereClause)
-	args := append(qb.setArgs, qb.args...)
-	return query, args
+	return qb.BuildUpdateQuery(tableName)
 }
 
 func (b *QueryBuilder) BuildInsertQuery(tableName string) (string, []interface{}) {
-	columns := strings.Join(b.columns, ", ")
+	if len(b.columns) == 0 {
+		b.setErr(ErrNoRows)
+		return "", nil
+	}
+	table := b.quoteTable(b.tableName(tableName))
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		columns[i] = b.quoteColumn(column)
+	}
 	placeholders := strings.Repeat("?, ", len(b.values)-1) + "?"
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", tableName, columns, placeholders)
-	return query, b.values
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders)
+	return b.failClosed(query, b.values)
 }
 
 func (b *QueryBuilder) BuildDeleteQuery(tableName string) (string, []interface{}) {
-	whereClause := buildWhereClause(b.conditions)
-	query := fmt.Sprintf("DELETE FROM %s %s", tableName, whereClause)
-	return query, b.args
+	table := b.quoteTable(b.tableName(tableName))
+	whereClause, whereArgs := b.buildWhereClause()
+	if whereClause == "" {
+		b.setErr(ErrNoConditions)
+	}
+	query := fmt.Sprintf("DELETE FROM %s %s", table, whereClause)
+	return b.failClosed(query, whereArgs)
 }
 
+// BuildCountQuery counts the rows matched by the builder, joins included.
+// Grouped and DISTINCT queries are wrapped in a derived table so that groups
+// or distinct rows, not source rows, are counted.
 func (qb *QueryBuilder) BuildCountQuery(tableName string) (string, []interface{}) {
-	baseQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	withClause, withArgs := qb.buildWithClause()
 
-	whereClause := buildWhereClause(qb.conditions)
-	if whereClause != "" {
-		baseQuery = fmt.Sprintf("%s %s", baseQuery, whereClause)
+	if qb.isGrouped() || qb.distinct || len(qb.unions) > 0 {
+		query, args := qb.buildSelectBody(tableName, false)
+		unionClause, unionArgs := qb.buildUnionClause()
+		query = fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS `counted`", joinClauses(query, unionClause))
+		return qb.failClosed(joinClauses(withClause, query), mergeArgs(withArgs, args, unionArgs))
 	}
 
-	return baseQuery, qb.args
+	table, fromArgs := qb.buildFrom(tableName)
+	joinClause, joinArgs := qb.buildJoinClause()
+	whereClause, whereArgs := qb.buildWhereClause()
+
+	query := joinClauses(withClause, "SELECT COUNT(*) FROM "+table, joinClause, whereClause)
+	return qb.failClosed(query, mergeArgs(withArgs, fromArgs, joinArgs, whereArgs))
 }
 
-func (qb *QueryBuilder) ExtractFieldsForInsert(data interface{}) error {
-	// Ensure the data is a pointer to a struct
-	val := reflect.ValueOf(data)
-	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("data must be a pointer to a struct")
+func (b *QueryBuilder) buildColumns() (string, []interface{}) {
+	if len(b.columns) == 0 && len(b.selectExprs) == 0 && len(b.aggregates) == 0 {
+		return "*", nil
 	}
-
-	// Dereference the pointer to access the struct
-	val = val.Elem()
-	typ := val.Type()
-
-	// Iterate over all fields of the struct
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
-
-		fieldValue := reflect.ValueOf(data).Elem().Field(i)
-
-		// Get the `db` tag to use as the column name
-		columnName := fieldType.Tag.Get("db")
-		if columnName == "" || fieldValue.IsZero() || columnName == "created_at" || columnName == "updated_at" || strings.Contains(columnName, "status") || columnName == "id" || (field.Kind() == reflect.Ptr && field.IsNil()) {
-			continue // Skip fields without a tag or that are nil
-		}
-
-		// Add the column and value to the query builder
-		qb.AddColumnValue(fmt.Sprintf("`%s`", columnName), field.Interface())
+	columns := make([]string, 0, len(b.columns)+len(b.selectExprs)+len(b.aggregates))
+	var args []interface{}
+	for _, column := range b.columns {
+		columns = append(columns, b.quoteSelectColumn(column))
 	}
-
-	return nil
+	for _, expr := range b.selectExprs {
+		columns = append(columns, expr.expr)
+		args = append(args, expr.args...)
+	}
+	for _, agg := range b.aggregates {
+		columns = append(columns, b.buildAggregate(agg))
+	}
+	return strings.Join(columns, ", "), args
 }
 
-func joinColumns(columns []string) string {
-	if len(columns) == 0 {
-		return "*"
+func (b *QueryBuilder) buildSetClause() string {
+	clauses := make([]string, len(b.setColumns))
+	for i, column := range b.setColumns {
+		clauses[i] = fmt.Sprintf("%s = ?", b.quoteColumn(column))
 	}
-	return strings.Join(columns, ", ")
+	return strings.Join(clauses, ", ")
 }
 
-func buildJoinClause(joins []JoinOptions) string {
-	if len(joins) == 0 {
-		return ""
+func (b *QueryBuilder) buildJoinClause() (string, []interface{}) {
+	if len(b.joins) == 0 {
+		return "", nil
 	}
 
-	joinClauses := make([]string, len(joins))
-	for i, join := range joins {
-		joinClauses[i] = fmt.Sprintf("%s JOIN %s ON %s", join.JoinType, join.Table, join.On)
+	var args []interface{}
+	clauses := make([]string, len(b.joins))
+	for i, join := range b.joins {
+		joinType := strings.ToUpper(strings.Join(strings.Fields(join.JoinType), " "))
+		if !joinTypes[joinType] {
+			b.setErr(fmt.Errorf("%q: %w", join.JoinType, ErrInvalidJoinType))
+			return "", nil
+		}
+
+		var table string
+		if join.subquery != nil {
+			var subArgs []interface{}
+			table, subArgs = b.buildDerivedTable(join.subquery, join.Alias)
+			args = append(args, subArgs...)
+		} else if join.Alias != "" {
+			table = b.quoteTable(join.Table + " " + join.Alias)
+		} else {
+			table = b.quoteTable(join.Table)
+		}
+
+		clauses[i] = joinClauses(joinType, "JOIN "+table)
+		if join.On != "" {
+			clauses[i] += " ON " + join.On
+			args = append(args, join.Args...)
+		}
 	}
-	return strings.Join(joinClauses, " ")
+	return strings.Join(clauses, " "), args
 }
 
-func buildPaginationClause(limit, offset int, orderBy string) string {
+// buildPaginationClause renders ORDER BY, LIMIT and OFFSET. A single row
+// query ignores keyset pagination and defaults to LIMIT 1.
+func (b *QueryBuilder) buildPaginationClause(single bool) string {
 	clauses := []string{}
 
-	if orderBy != "" {
+	if orderBy := b.buildOrderBy(); orderBy != "" {
 		clauses = append(clauses, fmt.Sprintf("ORDER BY %s", orderBy))
 	}
 
+	if b.keyset != nil && !single {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", b.keyset.Fetch()))
+		return strings.Join(clauses, " ")
+	}
+
+	offset, limit := b.window()
+	if single && limit <= 0 {
+		limit = 1
+	}
 	if limit > 0 {
 		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	} else if offset > 0 {
+		// MySQL has no OFFSET without LIMIT, so use the largest row count
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", uint64(math.MaxUint64)))
 	}
 
 	if offset > 0 {
-		clauses = append(clauses, fmt.Sprintf("OFFSET %d", (offset-1)*(limit)))
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
 	}
 
 	return strings.Join(clauses, " ")
 }
 
-func buildWhereClause(conditions []string) string {
-	if len(conditions) == 0 {
-		return ""
+// buildWhereClause renders the conditions, AND-ed with extra. The
+// conditions are grouped first when there are several, so that an OR among
+// them cannot bypass extra.
+func (b *QueryBuilder) buildWhereClause(extra ...conditionNode) (string, []interface{}) {
+	conditions := b.conditions
+	if len(extra) > 0 {
+		if len(conditions) > 1 {
+			conditions = []conditionNode{{conjunction: "AND", group: conditions}}
+		}
+		conditions = append(append([]conditionNode{}, conditions...), extra...)
+	}
+	expr, args := b.buildConditions(conditions)
+	if expr == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("WHERE %s", expr), args
+}
+
+// buildConditions renders a list of sibling nodes. Raw fragments are wrapped
+// in parentheses when they have siblings so that an `a OR b` fragment cannot
+// change the precedence of the surrounding conjunctions.
+func (b *QueryBuilder) buildConditions(nodes []conditionNode) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+
+	wrap := len(nodes) > 1
+	for _, node := range nodes {
+		expr, nodeArgs := node.build(b, wrap)
+		if expr == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(" " + node.conjunction + " ")
+		}
+		sb.WriteString(expr)
+		args = append(args, nodeArgs...)
+	}
+	return sb.String(), args
+}
+
+func (n conditionNode) build(b *QueryBuilder, wrap bool) (string, []interface{}) {
+	expr, args := n.expr, n.args
+	if n.predicate != nil {
+		expr, args = n.predicate.build(b)
+	}
+	if n.group != nil {
+		expr, args = b.buildConditions(n.group)
+		wrap = true
+	} else if n.atomic {
+		wrap = false
+	}
+	if expr == "" {
+		return "", nil
+	}
+
+	if n.negate {
+		return fmt.Sprintf("NOT (%s)", expr), args
+	}
+	if wrap {
+		return fmt.Sprintf("(%s)", expr), args
+	}
+	return expr, args
+}
+
+// joinClauses joins the non-empty clauses of a statement with single spaces.
+func joinClauses(clauses ...string) string {
+	parts := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		if clause != "" {
+			parts = append(parts, clause)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func normalizeConjunction(conjunction string) (string, error) {
+	switch normalized := strings.ToUpper(strings.TrimSpace(conjunction)); normalized {
+	case "":
+		return "AND", nil
+	case "AND", "OR", "XOR":
+		return normalized, nil
+	}
+	return "", fmt.Errorf("%q: %w", conjunction, ErrInvalidConjunction)
+}
+
+func mergeArgs(parts ...[]interface{}) []interface{} {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	args := make([]interface{}, 0, size)
+	for _, part := range parts {
+		args = append(args, part...)
 	}
-	return fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+	return args
 }
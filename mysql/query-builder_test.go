@@ -0,0 +1,315 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWhereWithConjunction(t *testing.T) {
+	tests := []struct {
+		conjunction string
+		want        string
+		err         error
+	}{
+		{conjunction: "", want: "SELECT * FROM `t` WHERE (a = ?) AND (b = ?)"},
+		{conjunction: "or", want: "SELECT * FROM `t` WHERE (a = ?) OR (b = ?)"},
+		{conjunction: " XOR ", want: "SELECT * FROM `t` WHERE (a = ?) XOR (b = ?)"},
+		{conjunction: "OR 1=1 OR", err: ErrInvalidConjunction},
+		{conjunction: "AND NOT", err: ErrInvalidConjunction},
+	}
+
+	for _, tt := range tests {
+		qb := NewQueryBuilder().Where("a = ?", 1).WhereWithConjunction(tt.conjunction, "b = ?", 2)
+		query, _ := qb.BuildSelectManyQuery("t")
+		if !errors.Is(qb.Err(), tt.err) {
+			t.Errorf("%q: err = %v, want %v", tt.conjunction, qb.Err(), tt.err)
+		}
+		if tt.err == nil && query != tt.want {
+			t.Errorf("%q: query = %q, want %q", tt.conjunction, query, tt.want)
+		}
+	}
+}
+
+func TestBuildUpdateQuery(t *testing.T) {
+	qb := NewQueryBuilder().Set("title", "x").Where(Eq("id", 3))
+	query, args := qb.BuildUpdateManyQuery("courses")
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE `courses` SET `title` = ? WHERE `id` = ?"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if want := []interface{}{"x", 3}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestBuildQueryRequiresConditions(t *testing.T) {
+	for name, build := range map[string]func(*QueryBuilder, string) (string, []interface{}){
+		"update":      (*QueryBuilder).BuildUpdateQuery,
+		"update many": (*QueryBuilder).BuildUpdateManyQuery,
+		"delete":      (*QueryBuilder).BuildDeleteQuery,
+	} {
+		qb := NewQueryBuilder().Set("title", "x")
+		query, _ := build(qb, "courses")
+		if !errors.Is(qb.Err(), ErrNoConditions) {
+			t.Errorf("%s: err = %v, want %v", name, qb.Err(), ErrNoConditions)
+		}
+		if query != "" {
+			t.Errorf("%s: query = %q, want empty", name, query)
+		}
+	}
+}
+
+func TestBuildDeleteQuery(t *testing.T) {
+	qb := NewQueryBuilder().Where(Eq("id", 3))
+	query, args := qb.BuildDeleteQuery("courses")
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "DELETE FROM `courses` WHERE `id` = ?"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if want := []interface{}{3}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestBuildPagination(t *testing.T) {
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+	}{
+		{
+			name: "deprecated offset is a page number",
+			qb:   NewQueryBuilder().Offset(3).Limit(10),
+			want: "SELECT * FROM `t` LIMIT 10 OFFSET 20",
+		},
+		{
+			name: "first page",
+			qb:   NewQueryBuilder().Limit(10).Offset(1),
+			want: "SELECT * FROM `t` LIMIT 10",
+		},
+		{
+			name: "skip rows",
+			qb:   NewQueryBuilder().Limit(10).Skip(3),
+			want: "SELECT * FROM `t` LIMIT 10 OFFSET 3",
+		},
+		{
+			name: "page",
+			qb:   NewQueryBuilder().Page(2, 25),
+			want: "SELECT * FROM `t` LIMIT 25 OFFSET 25",
+		},
+	}
+
+	for _, tt := range tests {
+		query, _ := tt.qb.BuildSelectManyQuery("t")
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+	}
+}
+
+func TestBuildSelectQueryLimitsToOneRow(t *testing.T) {
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+		args []interface{}
+	}{
+		{
+			name: "locking read",
+			qb:   NewQueryBuilder().Where("status = ?", "pending").OrderByAsc("id").Limit(1).ForUpdate().SkipLocked(),
+			want: "SELECT * FROM `jobs` WHERE status = ? ORDER BY `id` ASC LIMIT 1 FOR UPDATE SKIP LOCKED",
+			args: []interface{}{"pending"},
+		},
+		{
+			name: "default limit",
+			qb:   NewQueryBuilder().Where("status = ?", "pending").OrderByAsc("id").ForUpdate(),
+			want: "SELECT * FROM `jobs` WHERE status = ? ORDER BY `id` ASC LIMIT 1 FOR UPDATE",
+			args: []interface{}{"pending"},
+		},
+		{
+			name: "caller limit and skip",
+			qb:   NewQueryBuilder().Limit(5).Skip(10),
+			want: "SELECT * FROM `jobs` LIMIT 5 OFFSET 10",
+			args: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		query, args := tt.qb.BuildSelectQuery("jobs")
+		if err := tt.qb.Err(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
+
+func TestSelectRaw(t *testing.T) {
+	qb := NewQueryBuilder().
+		AllowColumns("id", "c.title", "status").
+		Select([]string{"id", "c.title AS course"}).
+		SelectRaw("COALESCE(grade, ?) AS grade", 0).
+		Where(Eq("status", "active"))
+	query, args := qb.BuildSelectManyQuery("courses AS c")
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT `id`, `c`.`title` AS `course`, COALESCE(grade, ?) AS grade FROM `courses` AS `c` WHERE `status` = ?"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if want := []interface{}{0, "active"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(*QueryBuilder) *QueryBuilder
+		want  string
+		err   error
+	}{
+		{
+			name: "replaces the sort",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.OrderBy("title").OrderBy("created_at DESC, id")
+			},
+			want: "SELECT * FROM `t` ORDER BY `created_at` DESC, `id` ASC",
+		},
+		{
+			name: "empty clears the sort",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.OrderByAsc("title").OrderBy("")
+			},
+			want: "SELECT * FROM `t`",
+		},
+		{
+			name: "structured keys append",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.OrderBy("grade desc").OrderByAsc("title").OrderByKeys(OrderKey{Column: "due_at", Nulls: NullsLast})
+			},
+			want: "SELECT * FROM `t` ORDER BY `grade` DESC, `title` ASC, `due_at` IS NULL ASC, `due_at` ASC",
+		},
+		{
+			name: "sort string",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.SortBy("-created,name", map[string]string{"created": "created_at", "name": "title"})
+			},
+			want: "SELECT * FROM `t` ORDER BY `created_at` DESC, `title` ASC",
+		},
+		{
+			name: "unknown sort field",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.SortBy("password", map[string]string{"name": "title"})
+			},
+			err: ErrIdentifierNotAllowed,
+		},
+		{
+			name: "raw expression",
+			build: func(qb *QueryBuilder) *QueryBuilder {
+				return qb.OrderBy("FIELD(status, 'a', 'b')")
+			},
+			err: ErrInvalidIdentifier,
+		},
+	}
+
+	for _, tt := range tests {
+		qb := tt.build(NewQueryBuilder())
+		query, _ := qb.BuildSelectManyQuery("t")
+		if !errors.Is(qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, qb.Err(), tt.err)
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+	}
+}
+
+func TestJoins(t *testing.T) {
+	tests := []struct {
+		name  string
+		qb    *QueryBuilder
+		count bool
+		want  string
+		args  []interface{}
+		err   error
+	}{
+		{
+			name: "parameterized joins",
+			qb: NewQueryBuilder().
+				Select([]string{"c.id", "s.name"}).
+				InnerJoin("enrollments e", "e.course_id = c.id AND e.status = ?", "active").
+				LeftJoin("students AS s", "s.id = e.student_id").
+				Where(Eq("c.school_id", 4)),
+			want: "SELECT `c`.`id`, `s`.`name` FROM `courses` AS `c` INNER JOIN `enrollments` AS `e` ON e.course_id = c.id AND e.status = ? LEFT JOIN `students` AS `s` ON s.id = e.student_id WHERE `c`.`school_id` = ?",
+			args: []interface{}{"active", 4},
+		},
+		{
+			name: "join options alias",
+			qb: NewQueryBuilder().
+				JoinWith(JoinOptions{JoinType: "right outer", Table: "teachers", Alias: "t", On: "t.id = c.teacher_id"}).
+				Join("cross", "terms", ""),
+			want: "SELECT * FROM `courses` AS `c` RIGHT OUTER JOIN `teachers` AS `t` ON t.id = c.teacher_id CROSS JOIN `terms`",
+			args: []interface{}{},
+		},
+		{
+			name: "subquery join",
+			qb: NewQueryBuilder().
+				JoinSubquery("LEFT", NewQueryBuilder().Select([]string{"course_id"}).Count("*", "n").From("enrollments").Where(Eq("status", "active")).GroupBy("course_id"), "e", "e.course_id = c.id AND e.n > ?", 10).
+				Where(Eq("c.school_id", 4)),
+			want: "SELECT * FROM `courses` AS `c` LEFT JOIN (SELECT `course_id`, COUNT(*) AS `n` FROM `enrollments` WHERE `status` = ? GROUP BY `course_id`) AS `e` ON e.course_id = c.id AND e.n > ? WHERE `c`.`school_id` = ?",
+			args: []interface{}{"active", 10, 4},
+		},
+		{
+			name:  "joins in counts",
+			qb:    NewQueryBuilder().InnerJoin("enrollments e", "e.course_id = c.id AND e.status = ?", "active").Where(Eq("c.school_id", 4)),
+			count: true,
+			want:  "SELECT COUNT(*) FROM `courses` AS `c` INNER JOIN `enrollments` AS `e` ON e.course_id = c.id AND e.status = ? WHERE `c`.`school_id` = ?",
+			args:  []interface{}{"active", 4},
+		},
+		{
+			name:  "distinct count over joins",
+			qb:    NewQueryBuilder().Select([]string{"c.id"}).Distinct().InnerJoin("enrollments e", "e.course_id = c.id"),
+			count: true,
+			want:  "SELECT COUNT(*) FROM (SELECT DISTINCT `c`.`id` FROM `courses` AS `c` INNER JOIN `enrollments` AS `e` ON e.course_id = c.id) AS `counted`",
+			args:  []interface{}{},
+		},
+		{
+			name: "invalid join type",
+			qb:   NewQueryBuilder().Join("LEFT; DROP TABLE x;", "students", "1 = 1"),
+			err:  ErrInvalidJoinType,
+		},
+		{
+			name: "invalid join table",
+			qb:   NewQueryBuilder().InnerJoin("students; DROP TABLE x", "1 = 1"),
+			err:  ErrInvalidIdentifier,
+		},
+	}
+
+	for _, tt := range tests {
+		build := tt.qb.BuildSelectManyQuery
+		if tt.count {
+			build = tt.qb.BuildCountQuery
+		}
+		query, args := build("courses c")
+		if !errors.Is(tt.qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.qb.Err(), tt.err)
+			continue
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if tt.err == nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SortDirection string
+
+const (
+	Asc  SortDirection = "ASC"
+	Desc SortDirection = "DESC"
+)
+
+// NullsOrder controls where NULL values are placed. MySQL has no NULLS
+// FIRST/LAST syntax, so it is emulated with an extra `column IS NULL` key.
+type NullsOrder int
+
+const (
+	NullsDefault NullsOrder = iota
+	NullsFirst
+	NullsLast
+)
+
+// OrderKey is a single ORDER BY entry.
+type OrderKey struct {
+	Column    string
+	Direction SortDirection
+	Nulls     NullsOrder
+}
+
+// ParseSort parses a `?sort=-created_at,name` style string into order keys.
+// A leading `-` sorts descending and an optional `+` ascending. Every field
+// must be a key of allowed, which maps the public field name to the column
+// used in the query.
+func ParseSort(sort string, allowed map[string]string) ([]OrderKey, error) {
+	var keys []OrderKey
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := Asc
+		switch field[0] {
+		case '-':
+			direction = Desc
+			field = field[1:]
+		case '+':
+			field = field[1:]
+		}
+
+		column, ok := allowed[field]
+		if !ok {
+			return nil, fmt.Errorf("sort field %q: %w", field, ErrIdentifierNotAllowed)
+		}
+		keys = append(keys, OrderKey{Column: column, Direction: direction})
+	}
+	return keys, nil
+}
+
+func (b *QueryBuilder) OrderByAsc(column string) *QueryBuilder {
+	return b.OrderByKeys(OrderKey{Column: column, Direction: Asc})
+}
+
+func (b *QueryBuilder) OrderByDesc(column string) *QueryBuilder {
+	return b.OrderByKeys(OrderKey{Column: column, Direction: Desc})
+}
+
+// OrderByKeys appends sort keys after the ones already added.
+func (b *QueryBuilder) OrderByKeys(keys ...OrderKey) *QueryBuilder {
+	b.orderBy = append(b.orderBy, keys...)
+	return b
+}
+
+// SortBy parses sort with ParseSort and appends the resulting keys. Unknown
+// fields are recorded as a builder error.
+func (b *QueryBuilder) SortBy(sort string, allowed map[string]string) *QueryBuilder {
+	keys, err := ParseSort(sort, allowed)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	return b.OrderByKeys(keys...)
+}
+
+// parseOrderBy parses the comma separated `column [ASC|DESC]` list accepted
+// by OrderBy.
+func parseOrderBy(orderBy string) ([]OrderKey, error) {
+	var keys []OrderKey
+	for _, key := range strings.Split(orderBy, ",") {
+		fields := strings.Fields(key)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid order by %q", orderBy)
+		}
+
+		direction := Asc
+		if len(fields) == 2 {
+			direction = SortDirection(strings.ToUpper(fields[1]))
+			if direction != Asc && direction != Desc {
+				return nil, fmt.Errorf("invalid sort direction %q", fields[1])
+			}
+		}
+		keys = append(keys, OrderKey{Column: fields[0], Direction: direction})
+	}
+	return keys, nil
+}
+
+func (b *QueryBuilder) buildOrderBy() string {
+	if len(b.orderBy) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(b.orderBy))
+	for _, key := range b.orderKeys() {
+		column := b.quoteColumn(key.Column)
+		direction := key.Direction
+		if direction == "" {
+			direction = Asc
+		}
+		if direction != Asc && direction != Desc {
+			b.setErr(fmt.Errorf("invalid sort direction %q", key.Direction))
+			return ""
+		}
+
+		switch key.Nulls {
+		case NullsFirst:
+			keys = append(keys, fmt.Sprintf("%s IS NULL DESC", column))
+		case NullsLast:
+			keys = append(keys, fmt.Sprintf("%s IS NULL ASC", column))
+		}
+		keys = append(keys, fmt.Sprintf("%s %s", column, direction))
+	}
+	return strings.Join(keys, ", ")
+}
@@ -6,16 +6,33 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ed-tech-connect/edtech-datasources/pagination"
 	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
 )
 
 type MySQLRepository struct {
-	db *sql.DB
-	Tx *sql.Tx
+	db      *sql.DB
+	Tx      *sql.Tx
+	scanner *sqlscan.Scanner
 }
 
-func NewMySQLRepository(db *sql.DB) IRepository {
-	return &MySQLRepository{db: db}
+// RepositoryOption configures a MySQLRepository.
+type RepositoryOption func(*MySQLRepository)
+
+// WithScanner scans query results with scanner instead of the sqlscan
+// package defaults.
+func WithScanner(scanner *sqlscan.Scanner) RepositoryOption {
+	return func(r *MySQLRepository) {
+		r.scanner = scanner
+	}
+}
+
+func NewMySQLRepository(db *sql.DB, opts ...RepositoryOption) IRepository {
+	r := &MySQLRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *MySQLRepository) BeginTransaction(ctx context.Context) (IUnitOfWork, error) {
@@ -23,7 +40,37 @@ func (r *MySQLRepository) BeginTransaction(ctx context.Context) (IUnitOfWork, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return &MySQLUnitOfWork{Tx: tx}, nil
+	return &MySQLUnitOfWork{Tx: tx, scanner: r.scanner}, nil
+}
+
+func (r *MySQLRepository) scanRow(result interface{}, rows sqlscan.RowsScanner) error {
+	if r.scanner != nil {
+		return r.scanner.Row(result, rows)
+	}
+	return sqlscan.Row(result, rows)
+}
+
+// scanRows scans into a slice of structs, or into dynamic rows when results
+// is a *[]map[string]interface{}.
+func (r *MySQLRepository) scanRows(results interface{}, rows sqlscan.RowsScanner) error {
+	if maps, ok := results.(*[]map[string]interface{}); ok {
+		var scanned []map[string]interface{}
+		var err error
+		if r.scanner != nil {
+			scanned, err = r.scanner.Maps(rows)
+		} else {
+			scanned, err = sqlscan.Maps(rows)
+		}
+		if err != nil {
+			return err
+		}
+		*maps = scanned
+		return nil
+	}
+	if r.scanner != nil {
+		return r.scanner.Rows(results, rows)
+	}
+	return sqlscan.Rows(results, rows)
 }
 
 func (r *MySQLRepository) getExecutor() (queryExecutor, error) {
@@ -35,6 +82,12 @@ func (r *MySQLRepository) getExecutor() (queryExecutor, error) {
 
 func (r *MySQLRepository) FindOne(ctx context.Context, tableName string, builder *QueryBuilder, result interface{}) error {
 	query, args := builder.BuildSelectQuery(tableName)
+	if err := builder.Err(); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	if builder.isLocking() && r.Tx == nil {
+		return ErrLockOutsideTransaction
+	}
 	executor, err := r.getExecutor()
 	if err != nil {
 		return err
@@ -44,7 +97,7 @@ func (r *MySQLRepository) FindOne(ctx context.Context, tableName string, builder
 	if err != nil {
 		return fmt.Errorf("error finding a record: %w", err)
 	}
-	if err := sqlscan.Row(result, row); err != nil {
+	if err := r.scanRow(result, row); err != nil {
 		if strings.EqualFold(err.Error(), "sql: no rows in result set") {
 			return nil
 		}
@@ -53,34 +106,79 @@ func (r *MySQLRepository) FindOne(ctx context.Context, tableName string, builder
 	return nil
 }
 
-func (r *MySQLRepository) FindMany(ctx context.Context, tableName string, builder *QueryBuilder, results interface{}) (int, error) {
+func (r *MySQLRepository) FindMany(ctx context.Context, tableName string, builder *QueryBuilder, results interface{}) (pagination.PageInfo, error) {
+	if builder.isLocking() && r.Tx == nil {
+		return pagination.PageInfo{}, ErrLockOutsideTransaction
+	}
+	if builder.keyset != nil {
+		return r.findKeyset(ctx, tableName, builder, results)
+	}
+
 	countQuery, countArgs := builder.BuildCountQuery(tableName)
+	query, args := builder.BuildSelectManyQuery(tableName)
+	if err := builder.Err(); err != nil {
+		return pagination.PageInfo{}, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
-		return 0, err
+		return pagination.PageInfo{}, err
 	}
 
-	var totalCount int
+	var totalCount int64
 	err = executor.QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount)
 	if err != nil {
-		return 0, fmt.Errorf("error fetching total count: %w", err)
+		return pagination.PageInfo{}, fmt.Errorf("error fetching total count: %w", err)
 	}
 
-	query, args := builder.BuildSelectManyQuery(tableName)
 	rows, err := executor.QueryContext(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("error finding many records: %w", err)
+		return pagination.PageInfo{}, fmt.Errorf("error finding many records: %w", err)
 	}
 	defer rows.Close()
-	if err := sqlscan.Rows(results, rows); err != nil {
-		return 0, fmt.Errorf("failed to scan rows: %w", err)
+	if err := r.scanRows(results, rows); err != nil {
+		return pagination.PageInfo{}, fmt.Errorf("failed to scan rows: %w", err)
+	}
+	skip, take := builder.window()
+	return pagination.NewPageInfo(totalCount, skip, take), nil
+}
+
+func (r *MySQLRepository) findKeyset(ctx context.Context, tableName string, builder *QueryBuilder, results interface{}) (pagination.PageInfo, error) {
+	query, args := builder.BuildSelectManyQuery(tableName)
+	if err := builder.Err(); err != nil {
+		return pagination.PageInfo{}, fmt.Errorf("invalid query: %w", err)
+	}
+
+	executor, err := r.getExecutor()
+	if err != nil {
+		return pagination.PageInfo{}, err
 	}
-	return totalCount, nil
+
+	return builder.keyset.Query(results, func(results interface{}) error {
+		rows, err := executor.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("error finding many records: %w", err)
+		}
+		defer rows.Close()
+		if err := r.scanRows(results, rows); err != nil {
+			return fmt.Errorf("failed to scan rows: %w", err)
+		}
+		return nil
+	}, builder.keysetValues)
+}
+
+// FindPage is FindMany returning a pagination.PagedResult.
+func FindPage[T any](ctx context.Context, repo IRepository, tableName string, builder *QueryBuilder) (pagination.PagedResult[T], error) {
+	return pagination.Collect[T](func(results interface{}) (pagination.PageInfo, error) {
+		return repo.FindMany(ctx, tableName, builder, results)
+	})
 }
 
 func (r *MySQLRepository) UpdateOne(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
 	query, args := qb.BuildUpdateQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
@@ -107,6 +205,9 @@ func (r *MySQLRepository) UpdateOne(ctx context.Context, tableName string, qb *Q
 
 func (r *MySQLRepository) UpdateMany(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
 	query, args := qb.BuildUpdateManyQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
@@ -133,6 +234,9 @@ func (r *MySQLRepository) UpdateMany(ctx context.Context, tableName string, qb *
 
 func (r *MySQLRepository) InsertOne(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
 	query, args := qb.BuildInsertQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
@@ -157,8 +261,176 @@ func (r *MySQLRepository) InsertOne(ctx context.Context, tableName string, qb *Q
 	return response, nil
 }
 
+// InsertMany inserts the rows of qb with as few multi-row INSERT statements
+// as the placeholder and packet limits allow.
+func (r *MySQLRepository) InsertMany(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
+	batches, err := qb.BuildInsertManyQueries(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	return r.atomic(ctx, func(repo *MySQLRepository) (map[string]interface{}, error) {
+		return repo.insertBatches(ctx, batches)
+	})
+}
+
+// atomic runs write on r inside its unit of work, or outside of one in a
+// transaction of its own, so that a failed multi-statement write leaves
+// nothing behind.
+func (r *MySQLRepository) atomic(ctx context.Context, write func(repo *MySQLRepository) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if r.Tx != nil {
+		return write(r)
+	}
+
+	uow, err := r.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response, err := write(uow.GetRepository().(*MySQLRepository))
+	if err != nil {
+		uow.Rollback()
+		return nil, err
+	}
+	if err := uow.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return response, nil
+}
+
+func (r *MySQLRepository) insertBatches(ctx context.Context, batches []InsertBatch) (map[string]interface{}, error) {
+	executor, err := r.getExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	insertedCount := 0
+	firstInsertedIDs := make([]int, 0, len(batches))
+	for _, batch := range batches {
+		result, err := executor.ExecContext(ctx, batch.Query, batch.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("error inserting records: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve rows affected: %w", err)
+		}
+		// for a multi-row insert MySQL reports the ID of the first row
+		insertedID, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve inserted ID: %w", err)
+		}
+
+		insertedCount += int(rowsAffected)
+		firstInsertedIDs = append(firstInsertedIDs, int(insertedID))
+	}
+
+	response := map[string]interface{}{
+		"acknowledged":     true,
+		"insertedCount":    insertedCount,
+		"firstInsertedIds": firstInsertedIDs,
+	}
+
+	return response, nil
+}
+
+func (r *MySQLRepository) UpsertOne(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
+	query, args := qb.BuildUpsertQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	executor, err := r.getExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := executor.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error upserting record: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	insertedID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve inserted ID: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"acknowledged": true,
+		"insertedId":   int(insertedID),
+		"status":       upsertStatus(rowsAffected),
+	}
+
+	return response, nil
+}
+
+// UpsertMany upserts the rows of qb one statement per row, reusing a single
+// prepared statement, because MySQL only reports whether a row was inserted
+// or updated for single-row statements.
+func (r *MySQLRepository) UpsertMany(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
+	if len(qb.rows) == 0 {
+		return nil, fmt.Errorf("invalid query: %w", ErrNoRows)
+	}
+	query, updateArgs := qb.buildUpsertStatement(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	return r.atomic(ctx, func(repo *MySQLRepository) (map[string]interface{}, error) {
+		return repo.upsertRows(ctx, query, qb.rows, updateArgs)
+	})
+}
+
+func (r *MySQLRepository) upsertRows(ctx context.Context, query string, rows [][]interface{}, updateArgs []interface{}) (map[string]interface{}, error) {
+	executor, err := r.getExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := executor.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	statuses := make([]string, len(rows))
+	counts := map[string]int{}
+	for i, row := range rows {
+		result, err := stmt.ExecContext(ctx, mergeArgs(row, updateArgs)...)
+		if err != nil {
+			return nil, fmt.Errorf("error upserting record %d: %w", i, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve rows affected: %w", err)
+		}
+
+		statuses[i] = upsertStatus(rowsAffected)
+		counts[statuses[i]]++
+	}
+
+	response := map[string]interface{}{
+		"acknowledged":   true,
+		"insertedCount":  counts[UpsertInserted],
+		"updatedCount":   counts[UpsertUpdated],
+		"unchangedCount": counts[UpsertUnchanged],
+		"statuses":       statuses,
+	}
+
+	return response, nil
+}
+
 func (r *MySQLRepository) DeleteOne(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
 	query, args := qb.BuildDeleteQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
@@ -185,6 +457,9 @@ func (r *MySQLRepository) DeleteOne(ctx context.Context, tableName string, qb *Q
 
 func (r *MySQLRepository) DeleteMany(ctx context.Context, tableName string, qb *QueryBuilder) (map[string]interface{}, error) {
 	query, args := qb.BuildDeleteQuery(tableName)
+	if err := qb.Err(); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
 
 	executor, err := r.getExecutor()
 	if err != nil {
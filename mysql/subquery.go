@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMissingTable is returned when a subquery is rendered without a table
+// set through From or FromSubquery.
+var ErrMissingTable = errors.New("subquery has no table")
+
+type subqueryPredicate struct {
+	column string
+	not    bool
+	sub    *QueryBuilder
+}
+
+func (p subqueryPredicate) build(b *QueryBuilder) (string, []interface{}) {
+	query, args := b.buildSubquery(p.sub)
+	op := "IN"
+	if p.not {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", b.quoteColumn(p.column), op, query), args
+}
+
+type existsPredicate struct {
+	not bool
+	sub *QueryBuilder
+}
+
+func (p existsPredicate) build(b *QueryBuilder) (string, []interface{}) {
+	query, args := b.buildSubquery(p.sub)
+	op := "EXISTS"
+	if p.not {
+		op = "NOT EXISTS"
+	}
+	return fmt.Sprintf("%s (%s)", op, query), args
+}
+
+// InSubquery renders `column IN (SELECT ...)`.
+func InSubquery(column string, sub *QueryBuilder) Predicate {
+	return subqueryPredicate{column: column, sub: sub}
+}
+
+func NotInSubquery(column string, sub *QueryBuilder) Predicate {
+	return subqueryPredicate{column: column, not: true, sub: sub}
+}
+
+// Exists renders `EXISTS (SELECT ...)`.
+func Exists(sub *QueryBuilder) Predicate {
+	return existsPredicate{sub: sub}
+}
+
+func NotExists(sub *QueryBuilder) Predicate {
+	return existsPredicate{not: true, sub: sub}
+}
+
+// From sets the table a builder selects from. It is required for builders
+// used as subqueries and is the fallback when a Build method receives an
+// empty table name.
+func (b *QueryBuilder) From(table string) *QueryBuilder {
+	b.from = table
+	return b
+}
+
+// FromSubquery selects from a derived table. It takes precedence over the
+// table name given to the Build methods.
+func (b *QueryBuilder) FromSubquery(sub *QueryBuilder, alias string) *QueryBuilder {
+	b.fromSubquery = sub
+	b.fromAlias = alias
+	return b
+}
+
+func (b *QueryBuilder) WhereInSubquery(column string, sub *QueryBuilder) *QueryBuilder {
+	return b.Where(InSubquery(column, sub))
+}
+
+func (b *QueryBuilder) WhereNotInSubquery(column string, sub *QueryBuilder) *QueryBuilder {
+	return b.Where(NotInSubquery(column, sub))
+}
+
+func (b *QueryBuilder) WhereExists(sub *QueryBuilder) *QueryBuilder {
+	return b.Where(Exists(sub))
+}
+
+func (b *QueryBuilder) WhereNotExists(sub *QueryBuilder) *QueryBuilder {
+	return b.Where(NotExists(sub))
+}
+
+// buildSubquery renders sub as a full SELECT and copies its error, if any,
+// to b.
+func (b *QueryBuilder) buildSubquery(sub *QueryBuilder) (string, []interface{}) {
+	if sub == nil {
+		b.setErr(fmt.Errorf("nil subquery: %w", ErrMissingTable))
+		return "", nil
+	}
+	if sub.from == "" && sub.fromSubquery == nil {
+		b.setErr(ErrMissingTable)
+		return "", nil
+	}
+
+	query, args := sub.buildSelect("", true)
+	if sub.err != nil {
+		b.setErr(sub.err)
+	}
+	return query, args
+}
+
+// buildDerivedTable renders `(SELECT ...) AS alias`.
+func (b *QueryBuilder) buildDerivedTable(sub *QueryBuilder, alias string) (string, []interface{}) {
+	aliasParts, err := parseIdentifier(alias, false)
+	if err != nil || len(aliasParts) != 1 {
+		b.setErr(fmt.Errorf("derived table alias %q: %w", alias, ErrInvalidIdentifier))
+		return "", nil
+	}
+	query, args := b.buildSubquery(sub)
+	return fmt.Sprintf("(%s) AS %s", query, quoteParts(aliasParts)), args
+}
+
+// buildFrom renders the FROM target: the derived table if one is set,
+// otherwise tableName or the table set with From.
+func (b *QueryBuilder) buildFrom(tableName string) (string, []interface{}) {
+	if b.fromSubquery != nil {
+		return b.buildDerivedTable(b.fromSubquery, b.fromAlias)
+	}
+	return b.quoteTable(b.tableName(tableName)), nil
+}
+
+func (b *QueryBuilder) tableName(tableName string) string {
+	if tableName == "" {
+		return b.from
+	}
+	return tableName
+}
@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubqueries(t *testing.T) {
+	active := func() *QueryBuilder {
+		return NewQueryBuilder().Select([]string{"student_id"}).From("enrollments").Where(Eq("status", "active"))
+	}
+
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+		args []interface{}
+		err  error
+	}{
+		{
+			name: "in subquery",
+			qb:   NewQueryBuilder().Where(Eq("school_id", 4)).WhereInSubquery("id", active()),
+			want: "SELECT * FROM `students` WHERE `school_id` = ? AND `id` IN (SELECT `student_id` FROM `enrollments` WHERE `status` = ?)",
+			args: []interface{}{4, "active"},
+		},
+		{
+			name: "not in subquery",
+			qb:   NewQueryBuilder().WhereNotInSubquery("id", active()),
+			want: "SELECT * FROM `students` WHERE `id` NOT IN (SELECT `student_id` FROM `enrollments` WHERE `status` = ?)",
+			args: []interface{}{"active"},
+		},
+		{
+			name: "correlated exists",
+			qb: NewQueryBuilder().
+				WhereExists(NewQueryBuilder().Select([]string{"*"}).From("enrollments AS e").Where("e.student_id = students.id").Where(Eq("e.grade", 5))).
+				WhereNotExists(NewQueryBuilder().From("suspensions").Where("suspensions.student_id = students.id")),
+			want: "SELECT * FROM `students` WHERE EXISTS (SELECT * FROM `enrollments` AS `e` WHERE (e.student_id = students.id) AND `e`.`grade` = ?) AND NOT EXISTS (SELECT * FROM `suspensions` WHERE suspensions.student_id = students.id)",
+			args: []interface{}{5},
+		},
+		{
+			name: "derived table",
+			qb: NewQueryBuilder().
+				FromSubquery(NewQueryBuilder().Select([]string{"student_id"}).Count("*", "courses").From("enrollments").GroupBy("student_id"), "per_student").
+				Where(Gt("courses", 3)),
+			want: "SELECT * FROM (SELECT `student_id`, COUNT(*) AS `courses` FROM `enrollments` GROUP BY `student_id`) AS `per_student` WHERE `courses` > ?",
+			args: []interface{}{3},
+		},
+		{
+			name: "paginated subquery keeps its limit",
+			qb:   NewQueryBuilder().WhereInSubquery("id", active().OrderByDesc("created_at").Limit(10)).Limit(5),
+			want: "SELECT * FROM `students` WHERE `id` IN (SELECT `student_id` FROM `enrollments` WHERE `status` = ? ORDER BY `created_at` DESC LIMIT 10) LIMIT 5",
+			args: []interface{}{"active"},
+		},
+		{
+			name: "subquery without table",
+			qb:   NewQueryBuilder().WhereExists(NewQueryBuilder().Where(Eq("id", 1))),
+			err:  ErrMissingTable,
+		},
+		{
+			name: "nil subquery",
+			qb:   NewQueryBuilder().WhereInSubquery("id", nil),
+			err:  ErrMissingTable,
+		},
+		{
+			name: "subquery error",
+			qb:   NewQueryBuilder().WhereInSubquery("id", NewQueryBuilder().From("enrollments").Select([]string{"id; DROP TABLE x"})),
+			err:  ErrInvalidIdentifier,
+		},
+		{
+			name: "invalid derived table alias",
+			qb:   NewQueryBuilder().FromSubquery(active(), "x y"),
+			err:  ErrInvalidIdentifier,
+		},
+	}
+
+	for _, tt := range tests {
+		query, args := tt.qb.BuildSelectManyQuery("students")
+		if !errors.Is(tt.qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.qb.Err(), tt.err)
+			continue
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if tt.err == nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -2,14 +2,17 @@ package mysql
 
 import (
 	"database/sql"
+
+	"github.com/ed-tech-connect/edtech-datasources/sqlscan"
 )
 
 type MySQLUnitOfWork struct {
-	Tx *sql.Tx
+	Tx      *sql.Tx
+	scanner *sqlscan.Scanner
 }
 
 func (uow *MySQLUnitOfWork) GetRepository() IRepository {
-	return &MySQLRepository{Tx: uow.Tx}
+	return &MySQLRepository{Tx: uow.Tx, scanner: uow.scanner}
 }
 
 func (uow *MySQLUnitOfWork) Commit() error {
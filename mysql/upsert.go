@@ -0,0 +1,143 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoUpdateColumns is returned when an upsert has no ON DUPLICATE KEY
+// UPDATE assignments.
+var ErrNoUpdateColumns = errors.New("upsert has no update columns")
+
+// Upsert outcomes as reported by MySQL through the affected row count.
+const (
+	UpsertInserted  = "inserted"
+	UpsertUpdated   = "updated"
+	UpsertUnchanged = "unchanged"
+)
+
+type duplicateKeyUpdate struct {
+	column    string
+	expr      string
+	args      []interface{}
+	increment bool
+}
+
+// OnDuplicateKeyUpdate overwrites columns with the values of the row that
+// was being inserted: `col = VALUES(col)`, or `col = alias.col` when a row
+// alias is set.
+func (b *QueryBuilder) OnDuplicateKeyUpdate(columns ...string) *QueryBuilder {
+	for _, column := range columns {
+		b.duplicateKeyUpdates = append(b.duplicateKeyUpdates, duplicateKeyUpdate{column: column})
+	}
+	return b
+}
+
+// OnDuplicateKeyIncrement adds delta to the stored value: `col = col + ?`.
+func (b *QueryBuilder) OnDuplicateKeyIncrement(column string, delta interface{}) *QueryBuilder {
+	b.duplicateKeyUpdates = append(b.duplicateKeyUpdates, duplicateKeyUpdate{
+		column:    column,
+		args:      []interface{}{delta},
+		increment: true,
+	})
+	return b
+}
+
+// OnDuplicateKeySet assigns a raw SQL expression to column, e.g.
+// `GREATEST(score, ?)`.
+func (b *QueryBuilder) OnDuplicateKeySet(column, expr string, args ...interface{}) *QueryBuilder {
+	b.duplicateKeyUpdates = append(b.duplicateKeyUpdates, duplicateKeyUpdate{
+		column: column,
+		expr:   expr,
+		args:   args,
+	})
+	return b
+}
+
+// RowAlias switches to the MySQL 8.0.19+ row alias form,
+// `INSERT ... VALUES (...) AS alias ON DUPLICATE KEY UPDATE col = alias.col`,
+// which replaces the deprecated VALUES() function.
+func (b *QueryBuilder) RowAlias(alias string) *QueryBuilder {
+	b.rowAlias = alias
+	return b
+}
+
+// BuildUpsertQuery builds an INSERT ... ON DUPLICATE KEY UPDATE for the
+// values added with AddColumnValue.
+func (b *QueryBuilder) BuildUpsertQuery(tableName string) (string, []interface{}) {
+	query, updateArgs := b.buildUpsertStatement(tableName)
+	return b.failClosed(query, mergeArgs(b.values, updateArgs))
+}
+
+// buildUpsertStatement renders a single-row upsert and returns the args of
+// the update assignments, which follow the inserted values.
+func (b *QueryBuilder) buildUpsertStatement(tableName string) (string, []interface{}) {
+	if len(b.columns) == 0 {
+		b.setErr(ErrNoRows)
+		return "", nil
+	}
+	if len(b.duplicateKeyUpdates) == 0 {
+		b.setErr(ErrNoUpdateColumns)
+		return "", nil
+	}
+
+	table := b.quoteTable(b.tableName(tableName))
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		columns[i] = b.quoteColumn(column)
+	}
+	placeholders := strings.Repeat("?, ", len(columns)-1) + "?"
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders)
+
+	alias := ""
+	if b.rowAlias != "" {
+		parts, err := parseIdentifier(b.rowAlias, false)
+		if err != nil || len(parts) != 1 {
+			b.setErr(fmt.Errorf("row alias %q: %w", b.rowAlias, ErrInvalidIdentifier))
+			return "", nil
+		}
+		alias = quoteParts(parts)
+		query += " AS " + alias
+	}
+
+	var args []interface{}
+	assignments := make([]string, len(b.duplicateKeyUpdates))
+	for i, update := range b.duplicateKeyUpdates {
+		column := b.quoteColumn(update.column)
+		switch {
+		case update.increment:
+			assignments[i] = fmt.Sprintf("%s = %s + ?", column, column)
+			args = append(args, update.args...)
+		case update.expr != "":
+			assignments[i] = fmt.Sprintf("%s = %s", column, update.expr)
+			args = append(args, update.args...)
+		case alias != "":
+			parts, err := parseIdentifier(update.column, false)
+			if err != nil {
+				b.setErr(err)
+				return "", nil
+			}
+			assignments[i] = fmt.Sprintf("%s = %s.%s", column, alias, quoteParts(parts[len(parts)-1:]))
+		default:
+			assignments[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		}
+	}
+
+	query += " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	return query, args
+}
+
+// upsertStatus maps the affected row count of a single-row upsert to its
+// outcome: 1 for an insert, 2 for an update and 0 when the existing row
+// already held the same values. Connections using CLIENT_FOUND_ROWS report
+// unchanged rows as 1 and cannot be told apart from inserts.
+func upsertStatus(rowsAffected int64) string {
+	switch rowsAffected {
+	case 1:
+		return UpsertInserted
+	case 2:
+		return UpsertUpdated
+	}
+	return UpsertUnchanged
+}
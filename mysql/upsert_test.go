@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuildUpsertQuery(t *testing.T) {
+	row := func() *QueryBuilder {
+		return NewQueryBuilder().AddColumnValue("student_id", 9).AddColumnValue("course_id", 7).AddColumnValue("score", 80)
+	}
+
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+		args []interface{}
+		err  error
+	}{
+		{
+			name: "values function",
+			qb:   row().OnDuplicateKeyUpdate("score"),
+			want: "INSERT INTO `grades` (`student_id`, `course_id`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `score` = VALUES(`score`)",
+			args: []interface{}{9, 7, 80},
+		},
+		{
+			name: "row alias",
+			qb:   row().RowAlias("new").OnDuplicateKeyUpdate("score", "grades.course_id"),
+			want: "INSERT INTO `grades` (`student_id`, `course_id`, `score`) VALUES (?, ?, ?) AS `new` ON DUPLICATE KEY UPDATE `score` = `new`.`score`, `grades`.`course_id` = `new`.`course_id`",
+			args: []interface{}{9, 7, 80},
+		},
+		{
+			name: "increment and expression args follow the values",
+			qb:   row().OnDuplicateKeyIncrement("attempts", 1).OnDuplicateKeySet("score", "GREATEST(score, ?)", 80),
+			want: "INSERT INTO `grades` (`student_id`, `course_id`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `attempts` = `attempts` + ?, `score` = GREATEST(score, ?)",
+			args: []interface{}{9, 7, 80, 1, 80},
+		},
+		{
+			name: "no update columns",
+			qb:   row(),
+			err:  ErrNoUpdateColumns,
+		},
+		{
+			name: "no values",
+			qb:   NewQueryBuilder().OnDuplicateKeyUpdate("score"),
+			err:  ErrNoRows,
+		},
+		{
+			name: "invalid row alias",
+			qb:   row().RowAlias("new.x").OnDuplicateKeyUpdate("score"),
+			err:  ErrInvalidIdentifier,
+		},
+		{
+			name: "invalid update column with row alias",
+			qb:   row().RowAlias("new").OnDuplicateKeyUpdate("score = 0, role"),
+			err:  ErrInvalidIdentifier,
+		},
+	}
+
+	for _, tt := range tests {
+		query, args := tt.qb.BuildUpsertQuery("grades")
+		if !errors.Is(tt.qb.Err(), tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.qb.Err(), tt.err)
+			continue
+		}
+		if query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.want)
+		}
+		if tt.err == nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
+
+func TestUpsertStatus(t *testing.T) {
+	tests := map[int64]string{0: UpsertUnchanged, 1: UpsertInserted, 2: UpsertUpdated}
+	for rowsAffected, want := range tests {
+		if got := upsertStatus(rowsAffected); got != want {
+			t.Errorf("upsertStatus(%d) = %q, want %q", rowsAffected, got, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrInvalidCursor is returned when a cursor token is malformed or its
+	// signature does not match.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrUnsupportedCursorValue is returned when a sort key value cannot be
+	// stored in a cursor.
+	ErrUnsupportedCursorValue = errors.New("unsupported cursor value")
+
+	// ErrNilCursorCodec is returned when cursors are encoded or decoded
+	// without a codec.
+	ErrNilCursorCodec = errors.New("nil cursor codec")
+)
+
+// Direction tells whether a cursor points at the rows after or before the
+// row it was taken from.
+type Direction string
+
+const (
+	Forward  Direction = "next"
+	Backward Direction = "prev"
+)
+
+// SortKey is a sort column and its direction as recorded in a cursor.
+type SortKey struct {
+	Column string `json:"c"`
+	Desc   bool   `json:"d,omitempty"`
+}
+
+// Cursor is the decoded form of a cursor token. Values holds the sort key
+// values of the row the cursor was taken from, in the order of Keys. A
+// cursor without values selects the first page.
+type Cursor struct {
+	Direction Direction
+	Keys      []SortKey
+	Values    []interface{}
+}
+
+// CursorCodec encodes cursors into opaque, URL-safe tokens signed with
+// HMAC-SHA256, so that clients cannot forge positions.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec returns a codec signing tokens with secret. It panics when
+// secret is empty, since anyone could then sign a cursor.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	if len(secret) == 0 {
+		panic("pagination: empty cursor secret")
+	}
+	return &CursorCodec{secret: secret}
+}
+
+type cursorPayload struct {
+	Direction Direction     `json:"d"`
+	Keys      []SortKey     `json:"k"`
+	Values    []cursorValue `json:"v"`
+}
+
+// cursorValue keeps the Go type of a sort key value, which plain JSON would
+// lose (int64 precision, time.Time, ObjectID).
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+// Encode returns the token for cursor.
+func (c *CursorCodec) Encode(cursor Cursor) (string, error) {
+	if c == nil {
+		return "", ErrNilCursorCodec
+	}
+	payload := cursorPayload{
+		Direction: cursor.Direction,
+		Keys:      cursor.Keys,
+		Values:    make([]cursorValue, len(cursor.Values)),
+	}
+	for i, v := range cursor.Values {
+		value, err := encodeCursorValue(v)
+		if err != nil {
+			return "", err
+		}
+		payload.Values[i] = value
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(data) + "." + enc.EncodeToString(c.sign(data)), nil
+}
+
+// Decode verifies token and returns the cursor it holds. An empty token
+// decodes to a forward cursor for the first page.
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	if c == nil {
+		return Cursor{}, ErrNilCursorCodec
+	}
+	if token == "" {
+		return Cursor{Direction: Forward}, nil
+	}
+
+	enc := base64.RawURLEncoding
+	data, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payloadData, err := enc.DecodeString(data)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	sigData, err := enc.DecodeString(sig)
+	if err != nil || !hmac.Equal(sigData, c.sign(payloadData)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(payloadData, &payload); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if payload.Direction != Forward && payload.Direction != Backward {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if len(payload.Keys) != len(payload.Values) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	cursor := Cursor{Direction: payload.Direction, Keys: payload.Keys, Values: make([]interface{}, len(payload.Values))}
+	for i, v := range payload.Values {
+		value, err := decodeCursorValue(v)
+		if err != nil {
+			return Cursor{}, err
+		}
+		cursor.Values[i] = value
+	}
+	return cursor, nil
+}
+
+func (c *CursorCodec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func encodeCursorValue(v interface{}) (cursorValue, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return cursorValue{}, fmt.Errorf("encode cursor: %w", err)
+		}
+		v = value
+	}
+
+	switch value := v.(type) {
+	case nil:
+		return cursorValue{Type: "null"}, nil
+	case time.Time:
+		return cursorValue{Type: "time", Value: value.Format(time.RFC3339Nano)}, nil
+	case primitive.DateTime:
+		return cursorValue{Type: "time", Value: value.Time().Format(time.RFC3339Nano)}, nil
+	case primitive.ObjectID:
+		return cursorValue{Type: "oid", Value: value.Hex()}, nil
+	case []byte:
+		return cursorValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(value)}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return cursorValue{Type: "null"}, nil
+		}
+		return encodeCursorValue(rv.Elem().Interface())
+	case reflect.Bool:
+		return cursorValue{Type: "bool", Value: fmt.Sprint(rv.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "int", Value: fmt.Sprint(rv.Int())}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "uint", Value: fmt.Sprint(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return cursorValue{}, fmt.Errorf("%v: %w", f, ErrUnsupportedCursorValue)
+		}
+		return cursorValue{Type: "float", Value: fmt.Sprint(rv.Float())}, nil
+	case reflect.String:
+		return cursorValue{Type: "string", Value: rv.String()}, nil
+	}
+	return cursorValue{}, fmt.Errorf("%T: %w", v, ErrUnsupportedCursorValue)
+}
+
+func decodeCursorValue(v cursorValue) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "string":
+		return v.Value, nil
+	case "time":
+		value, err = time.Parse(time.RFC3339Nano, v.Value)
+	case "oid":
+		value, err = primitive.ObjectIDFromHex(v.Value)
+	case "bytes":
+		value, err = base64.StdEncoding.DecodeString(v.Value)
+	case "bool", "int", "uint", "float":
+		dec := json.NewDecoder(bytes.NewReader([]byte(v.Value)))
+		dec.UseNumber()
+		var raw interface{}
+		if err = dec.Decode(&raw); err != nil {
+			break
+		}
+		switch n := raw.(type) {
+		case bool:
+			value = n
+		case json.Number:
+			value, err = parseCursorNumber(v.Type, n)
+		default:
+			err = ErrInvalidCursor
+		}
+	default:
+		err = ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return value, nil
+}
+
+func parseCursorNumber(typ string, n json.Number) (interface{}, error) {
+	switch typ {
+	case "int":
+		return n.Int64()
+	case "uint":
+		var u uint64
+		_, err := fmt.Sscan(n.String(), &u)
+		return u, err
+	case "float":
+		return n.Float64()
+	}
+	return nil, ErrInvalidCursor
+}
@@ -0,0 +1,132 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	oid := primitive.NewObjectID()
+	cursor := Cursor{
+		Direction: Backward,
+		Keys:      []SortKey{{Column: "created_at", Desc: true}, {Column: "id"}},
+		Values:    []interface{}{time.Date(2024, 5, 1, 12, 0, 0, 5, time.UTC), int64(1) << 60},
+	}
+
+	token, err := codec.Encode(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := codec.Decode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("Decode = %+v, want %+v", got, cursor)
+	}
+
+	values := []interface{}{nil, "a.b", true, uint64(7), 1.5, []byte{0, 1}, oid}
+	keys := make([]SortKey, len(values))
+	for i := range keys {
+		keys[i] = SortKey{Column: string(rune('a' + i))}
+	}
+	token, err = codec.Encode(Cursor{Direction: Forward, Keys: keys, Values: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = codec.Decode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Values, values) {
+		t.Errorf("Values = %#v, want %#v", got.Values, values)
+	}
+}
+
+func TestCursorDecodeRejectsTampering(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	token, err := codec.Encode(Cursor{Direction: Forward, Keys: []SortKey{{Column: "id"}}, Values: []interface{}{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, sig, _ := strings.Cut(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"d":"next","k":[{"c":"id"}],"v":[{"t":"int","v":"2"}]}`))
+
+	tests := map[string]string{
+		"other secret":   mustEncode(t, NewCursorCodec([]byte("other")), Cursor{Direction: Forward, Keys: []SortKey{{Column: "id"}}, Values: []interface{}{1}}),
+		"forged payload": forged + "." + sig,
+		"no signature":   data,
+		"bad base64":     "!!." + sig,
+		"truncated sig":  data + "." + sig[:len(sig)-2],
+	}
+	for name, token := range tests {
+		if _, err := codec.Decode(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+
+	if cursor, err := codec.Decode(""); err != nil || cursor.Direction != Forward || cursor.Values != nil {
+		t.Errorf("Decode(\"\") = %+v, %v, want a first page cursor", cursor, err)
+	}
+}
+
+func TestCursorEncodeRejectsUnsupportedValues(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	_, err := codec.Encode(Cursor{Direction: Forward, Keys: []SortKey{{Column: "id"}}, Values: []interface{}{struct{}{}}})
+	if !errors.Is(err, ErrUnsupportedCursorValue) {
+		t.Errorf("err = %v, want ErrUnsupportedCursorValue", err)
+	}
+}
+
+func mustEncode(t *testing.T, codec *CursorCodec, cursor Cursor) string {
+	t.Helper()
+	token, err := codec.Encode(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestNewCursorCodecRequiresSecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewCursorCodec(%q) did not panic", secret)
+				}
+			}()
+			NewCursorCodec(secret)
+		}()
+	}
+}
+
+func TestNilCursorCodec(t *testing.T) {
+	var codec *CursorCodec
+	if _, err := codec.Decode("x.y"); !errors.Is(err, ErrNilCursorCodec) {
+		t.Errorf("Decode: err = %v, want ErrNilCursorCodec", err)
+	}
+	if _, err := codec.Encode(Cursor{Direction: Forward}); !errors.Is(err, ErrNilCursorCodec) {
+		t.Errorf("Encode: err = %v, want ErrNilCursorCodec", err)
+	}
+	if _, err := NewKeyset(nil, "x", 10); !errors.Is(err, ErrNilCursorCodec) {
+		t.Errorf("NewKeyset: err = %v, want ErrNilCursorCodec", err)
+	}
+}
+
+func TestCursorEncodeRejectsNonFiniteFloats(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := codec.Encode(Cursor{Direction: Forward, Keys: []SortKey{{Column: "score"}}, Values: []interface{}{f}})
+		if !errors.Is(err, ErrUnsupportedCursorValue) {
+			t.Errorf("%v: err = %v, want ErrUnsupportedCursorValue", f, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrKeysetRequiresSort is returned when keyset pagination is requested
+	// on a query without sort keys.
+	ErrKeysetRequiresSort = errors.New("keyset pagination requires sort keys")
+
+	// ErrInvalidPageSize is returned when a keyset page size is not positive.
+	ErrInvalidPageSize = errors.New("page size must be positive")
+)
+
+// Keyset is a keyset (seek) pagination request. Rows are located relative
+// to the sort key values stored in Cursor instead of by skipping rows, so
+// the cost of a page does not grow with its position. The sort keys must
+// identify a row uniquely, e.g. by ending with the primary key.
+type Keyset struct {
+	Codec  *CursorCodec
+	Cursor Cursor
+	Size   int64
+
+	keys []SortKey
+}
+
+// NewKeyset decodes token with codec. An empty token selects the first
+// page.
+func NewKeyset(codec *CursorCodec, token string, size int64) (*Keyset, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("%d: %w", size, ErrInvalidPageSize)
+	}
+	cursor, err := codec.Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyset{Codec: codec, Cursor: cursor, Size: size}, nil
+}
+
+// Backward reports whether the rows before the cursor are requested, in
+// which case the query must run with every sort direction inverted.
+func (k *Keyset) Backward() bool {
+	return k.Cursor.Direction == Backward
+}
+
+// Fetch is the number of rows to query: one more than Size, so that the
+// existence of a further page can be detected.
+func (k *Keyset) Fetch() int64 {
+	return k.Size + 1
+}
+
+// CheckKeys verifies that the cursor was issued for the same sort keys, so
+// that a token from one listing cannot be replayed against another. The
+// keys are recorded in the cursors that Page returns.
+func (k *Keyset) CheckKeys(keys []SortKey) error {
+	if len(keys) == 0 {
+		return ErrKeysetRequiresSort
+	}
+	k.keys = keys
+	if len(k.Cursor.Values) == 0 {
+		return nil
+	}
+	if len(k.Cursor.Keys) != len(keys) {
+		return fmt.Errorf("cursor has %d sort keys, query has %d: %w", len(k.Cursor.Keys), len(keys), ErrInvalidCursor)
+	}
+	for i, key := range keys {
+		if k.Cursor.Keys[i] != key {
+			return fmt.Errorf("cursor sort key %d is %+v, query has %+v: %w", i, k.Cursor.Keys[i], key, ErrInvalidCursor)
+		}
+	}
+	return nil
+}
+
+// Query runs a keyset page. It skips the count query, which would defeat
+// the purpose on large tables, and reports the position through cursors
+// instead. find fills results with up to Fetch rows; see Page for the
+// other arguments.
+func (k *Keyset) Query(results interface{}, find func(results interface{}) error, keyValues func(item interface{}) ([]interface{}, error)) (PageInfo, error) {
+	if err := find(results); err != nil {
+		return PageInfo{}, err
+	}
+	info, err := k.Page(results, keyValues)
+	if err != nil {
+		return PageInfo{}, fmt.Errorf("failed to build cursors: %w", err)
+	}
+	return info, nil
+}
+
+// Page trims results, a pointer to the slice filled by the query, to Size
+// and restores the sort order of backward pages. It returns the page info
+// with the next and previous cursor tokens; keyValues extracts the sort key
+// values of a single row.
+func (k *Keyset) Page(results interface{}, keyValues func(item interface{}) ([]interface{}, error)) (PageInfo, error) {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return PageInfo{}, fmt.Errorf("keyset results must be a slice pointer, got %T", results)
+	}
+	slice = slice.Elem()
+
+	more := int64(slice.Len()) > k.Size
+	if more {
+		slice.Set(slice.Slice(0, int(k.Size)))
+	}
+	if k.Backward() {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	info := PageInfo{PageSize: k.Size}
+	if k.Backward() {
+		info.HasNext = true
+		info.HasPrevious = more
+	} else {
+		info.HasNext = more
+		info.HasPrevious = len(k.Cursor.Values) > 0
+	}
+
+	if slice.Len() == 0 {
+		return info, nil
+	}
+
+	var err error
+	if info.HasNext {
+		info.NextCursor, err = k.encode(Forward, slice.Index(slice.Len()-1), keyValues)
+		if err != nil {
+			return PageInfo{}, err
+		}
+	}
+	if info.HasPrevious {
+		info.PrevCursor, err = k.encode(Backward, slice.Index(0), keyValues)
+		if err != nil {
+			return PageInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+func (k *Keyset) encode(direction Direction, item reflect.Value, keyValues func(item interface{}) ([]interface{}, error)) (string, error) {
+	if item.Kind() != reflect.Ptr && item.CanAddr() {
+		item = item.Addr()
+	}
+	values, err := keyValues(item.Interface())
+	if err != nil {
+		return "", fmt.Errorf("cursor values: %w", err)
+	}
+	return k.Codec.Encode(Cursor{Direction: direction, Keys: k.keys, Values: values})
+}
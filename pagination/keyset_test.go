@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type row struct {
+	ID int64
+}
+
+func rowKey(item interface{}) ([]interface{}, error) {
+	return []interface{}{item.(*row).ID}, nil
+}
+
+func TestNewKeysetRejectsSize(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	for _, size := range []int64{0, -1} {
+		if _, err := NewKeyset(codec, "", size); !errors.Is(err, ErrInvalidPageSize) {
+			t.Errorf("size %d: err = %v, want ErrInvalidPageSize", size, err)
+		}
+	}
+}
+
+func TestKeysetCheckKeys(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	byID := []SortKey{{Column: "id"}}
+
+	first, err := NewKeyset(codec, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.CheckKeys(nil); !errors.Is(err, ErrKeysetRequiresSort) {
+		t.Errorf("no keys: err = %v, want ErrKeysetRequiresSort", err)
+	}
+	if err := first.CheckKeys(byID); err != nil {
+		t.Fatal(err)
+	}
+	results := []row{{1}, {2}, {3}}
+	info, err := first.Page(&results, rowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		keys []SortKey
+		err  error
+	}{
+		{name: "same keys", keys: byID},
+		{name: "other column", keys: []SortKey{{Column: "score"}}, err: ErrInvalidCursor},
+		{name: "other direction", keys: []SortKey{{Column: "id", Desc: true}}, err: ErrInvalidCursor},
+		{name: "more keys", keys: []SortKey{{Column: "id"}, {Column: "name"}}, err: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		next, err := NewKeyset(codec, info.NextCursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := next.CheckKeys(tt.keys); !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	keys := []SortKey{{Column: "id"}}
+
+	first, _ := NewKeyset(codec, "", 2)
+	first.CheckKeys(keys)
+	results := []row{{1}, {2}, {3}}
+	info, err := first.Page(&results, rowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, []row{{1}, {2}}) || !info.HasNext || info.HasPrevious || info.PrevCursor != "" {
+		t.Fatalf("first page = %v, %+v", results, info)
+	}
+
+	next, err := NewKeyset(codec, info.NextCursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Backward() || !reflect.DeepEqual(next.Cursor.Values, []interface{}{int64(2)}) {
+		t.Errorf("next cursor = %+v", next.Cursor)
+	}
+
+	// A backward page is queried in reverse order and restored by Page.
+	next.CheckKeys(keys)
+	results = []row{{3}}
+	info, err = next.Page(&results, rowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := NewKeyset(codec, info.PrevCursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !prev.Backward() || info.HasNext {
+		t.Fatalf("prev cursor = %+v, info = %+v", prev.Cursor, info)
+	}
+	prev.CheckKeys(keys)
+	results = []row{{2}, {1}}
+	info, err = prev.Page(&results, rowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, []row{{1}, {2}}) || !info.HasNext || info.HasPrevious {
+		t.Errorf("previous page = %v, %+v", results, info)
+	}
+}
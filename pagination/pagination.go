@@ -0,0 +1,90 @@
+// Package pagination holds the paging model shared by the mysql and mongo
+// repositories.
+package pagination
+
+// Paginator selects a window of rows. Skip is the number of rows to skip
+// and Take the maximum number of rows to return, where 0 means no limit.
+type Paginator interface {
+	Window() (skip, take int64)
+}
+
+// Page selects rows by a 1-based page number and a page size.
+type Page struct {
+	Number int64 `json:"number"`
+	Size   int64 `json:"size"`
+}
+
+// Window implements Paginator. Page numbers below 1 are treated as 1.
+func (p Page) Window() (int64, int64) {
+	if p.Size <= 0 {
+		return 0, 0
+	}
+	number := p.Number
+	if number < 1 {
+		number = 1
+	}
+	return (number - 1) * p.Size, p.Size
+}
+
+// Offset selects rows by a raw number of rows to skip and to take.
+type Offset struct {
+	Skip int64 `json:"skip"`
+	Take int64 `json:"take"`
+}
+
+// Window implements Paginator. Negative values are treated as 0.
+func (o Offset) Window() (int64, int64) {
+	return max(o.Skip, 0), max(o.Take, 0)
+}
+
+// PageInfo describes where a page of results sits within the full result
+// set. Keyset pages carry cursor tokens instead of a total and page number.
+type PageInfo struct {
+	Total       int64  `json:"total"`
+	Page        int64  `json:"page"`
+	PageSize    int64  `json:"pageSize"`
+	PageCount   int64  `json:"pageCount"`
+	HasNext     bool   `json:"hasNext"`
+	HasPrevious bool   `json:"hasPrevious"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
+}
+
+// NewPageInfo computes the page metadata for a window of skip/take rows out
+// of total. A take of 0 means everything from skip onwards is one page.
+func NewPageInfo(total, skip, take int64) PageInfo {
+	info := PageInfo{
+		Total:       total,
+		Page:        1,
+		PageSize:    take,
+		PageCount:   1,
+		HasPrevious: skip > 0,
+	}
+
+	if take <= 0 {
+		info.PageSize = max(total-skip, 0)
+		return info
+	}
+
+	info.Page = skip/take + 1
+	info.PageCount = (total + take - 1) / take
+	info.HasNext = skip+take < total
+	return info
+}
+
+// PagedResult is a page of items together with its PageInfo.
+type PagedResult[T any] struct {
+	Items []T `json:"items"`
+	PageInfo
+}
+
+// Collect runs find, a repository FindMany bound to its query, into a []T
+// and returns the items with their page metadata.
+func Collect[T any](find func(results interface{}) (PageInfo, error)) (PagedResult[T], error) {
+	var items []T
+	info, err := find(&items)
+	if err != nil {
+		return PagedResult[T]{}, err
+	}
+	return PagedResult[T]{Items: items, PageInfo: info}, nil
+}
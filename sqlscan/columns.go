@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"sync"
 )
 
 const dbTag = "db"
@@ -32,11 +31,13 @@ var (
 	// ColumnsMapper transforms struct/map field names
 	// into the database column names.
 	// E.g. you can set function for convert CamelCase into snake_case
+	//
+	// Deprecated: ColumnsMapper is read once, by the first package function
+	// that uses it, and later assignments are ignored. Use a Scanner
+	// configured with WithColumnsMapper or WithNaming instead.
 	ColumnsMapper = func(name string) string { return name }
 )
 
-var columnsCache cache = &sync.Map{}
-
 type cacheKey struct {
 	Type   reflect.Type
 	Strict bool
@@ -48,24 +49,25 @@ type cacheKey struct {
 // tag that matches a string within the excluded list
 // will be excluded from the result.
 func Columns(v interface{}, excluded ...string) ([]string, error) {
-	return columns(v, false, excluded...)
+	return defaultScanner(false).columns(v, excluded...)
 }
 
 // ColumnsStrict is identical to Columns, but it only
 // searches struct tags and excludes fields not tagged
 // with the db struct tag.
 func ColumnsStrict(v interface{}, excluded ...string) ([]string, error) {
-	return columns(v, true, excluded...)
+	return defaultScanner(true).columns(v, excluded...)
 }
 
-func columns(v interface{}, strict bool, excluded ...string) ([]string, error) {
+func (s *Scanner) columns(v interface{}, excluded ...string) ([]string, error) {
 	model, err := reflectValue(v)
 	if err != nil {
 		return nil, fmt.Errorf("columns: %w", err)
 	}
 
-	key := cacheKey{model.Type(), strict}
+	key := cacheKey{model.Type(), s.strict}
 
+	_, columnsCache := s.caches()
 	if cache, ok := columnsCache.Load(key); ok {
 		cached := cache.([]string)
 		res := make([]string, 0, len(cached))
@@ -87,13 +89,13 @@ func columns(v interface{}, strict bool, excluded ...string) ([]string, error) {
 		return res, nil
 	}
 
-	names := columnNames(model, strict, excluded...)
+	names := s.columnNames(model, "", excluded...)
 	toCache := append(names, excluded...)
 	columnsCache.Store(key, toCache)
 	return names, nil
 }
 
-func columnNames(model reflect.Value, strict bool, excluded ...string) []string {
+func (s *Scanner) columnNames(model reflect.Value, prefix string, excluded ...string) []string {
 	numfield := model.NumField()
 	names := make([]string, 0, numfield)
 
@@ -105,19 +107,21 @@ func columnNames(model reflect.Value, strict bool, excluded ...string) []string
 
 		typeField := model.Type().Field(i)
 
-		if typeField.Type.Kind() == reflect.Struct && !isValidSqlValue(valField) {
-			embeddedNames := columnNames(valField, strict, excluded...)
+		if nested, ok := NestedPrefix(typeField); ok {
+			embeddedNames := s.columnNames(valField, prefix+nested, excluded...)
 			names = append(names, embeddedNames...)
 			continue
 		}
 
-		fieldName := ColumnsMapper(typeField.Name)
+		fieldName := prefix + s.columnName(typeField.Name)
+		var json bool
 		if tag, hasTag := typeField.Tag.Lookup(dbTag); hasTag {
 			if tag == "-" {
 				continue
 			}
-			fieldName = tag
-		} else if strict {
+			name, opts := ParseTag(tag)
+			fieldName, json = prefix+name, opts.JSON
+		} else if s.strict {
 			// there's no tag name and we're in strict mode so move on
 			continue
 		}
@@ -126,7 +130,8 @@ func columnNames(model reflect.Value, strict bool, excluded ...string) []string
 			continue
 		}
 
-		if supportedColumnType(valField) || isValidSqlValue(valField) {
+		// JSON columns hold any type, which is encoded as a document
+		if json || supportedColumnType(valField) || isValidSqlValue(valField) {
 			names = append(names, fieldName)
 		}
 	}
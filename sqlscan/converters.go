@@ -0,0 +1,205 @@
+package sqlscan
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ScanFunc converts a driver value, e.g. the []byte of a DECIMAL column,
+// into the value stored in the scan destination.
+type ScanFunc func(src interface{}) (interface{}, error)
+
+// ValueFunc converts a field value into a driver value for a query argument.
+type ValueFunc func(v interface{}) (driver.Value, error)
+
+// ConverterRegistry holds the conversions applied while scanning and by
+// Values. Scan conversions are registered per Go type of the destination
+// field or per database type name as reported by ColumnTypes; a Go type
+// conversion wins over a column one. A registry is safe for concurrent use.
+type ConverterRegistry struct {
+	mu      sync.RWMutex
+	types   map[reflect.Type]ScanFunc
+	columns map[string]ScanFunc
+	values  map[reflect.Type]ValueFunc
+	version atomic.Uint64
+}
+
+// Converters is the registry used by the package functions and by Scanners
+// created without WithConverters.
+var Converters = NewConverterRegistry()
+
+// NewConverterRegistry returns an empty registry.
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{
+		types:   map[reflect.Type]ScanFunc{},
+		columns: map[string]ScanFunc{},
+		values:  map[reflect.Type]ValueFunc{},
+	}
+}
+
+// RegisterScanType converts values scanned into fields of type T, e.g. a
+// decimal type or a UUID stored as BINARY(16).
+func RegisterScanType[T any](r *ConverterRegistry, fn func(src interface{}) (T, error)) {
+	r.register(func() {
+		r.types[reflect.TypeFor[T]()] = func(src interface{}) (interface{}, error) {
+			return fn(src)
+		}
+	})
+}
+
+// RegisterValueType converts fields of type T before they are returned by
+// Values, the inverse of RegisterScanType.
+func RegisterValueType[T any](r *ConverterRegistry, fn func(v T) (driver.Value, error)) {
+	r.register(func() {
+		r.values[reflect.TypeFor[T]()] = func(v interface{}) (driver.Value, error) {
+			return fn(v.(T))
+		}
+	})
+}
+
+// RegisterColumn converts the values of columns whose database type name
+// is typeName, e.g. "BIT" or "DECIMAL". The result must be assignable or
+// convertible to the destination field; in Maps it is stored as is.
+func (r *ConverterRegistry) RegisterColumn(typeName string, fn ScanFunc) {
+	r.register(func() {
+		r.columns[strings.ToUpper(typeName)] = fn
+	})
+}
+
+func (r *ConverterRegistry) register(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+	r.version.Add(1)
+}
+
+// empty reports whether no scan conversion is registered, in which case
+// column types need not be read.
+func (r *ConverterRegistry) empty() bool {
+	if r == nil {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.types) == 0 && len(r.columns) == 0
+}
+
+// scanFunc returns the conversion for a destination of type typ holding a
+// column of type typeName, or nil.
+func (r *ConverterRegistry) scanFunc(typ reflect.Type, typeName string) ScanFunc {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.types[typ]; ok {
+		return fn
+	}
+	return r.columns[strings.ToUpper(typeName)]
+}
+
+func (r *ConverterRegistry) columnFunc(typeName string) ScanFunc {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.columns[strings.ToUpper(typeName)]
+}
+
+func (r *ConverterRegistry) value(v interface{}) (interface{}, error) {
+	if r == nil || v == nil {
+		return v, nil
+	}
+	r.mu.RLock()
+	fn, ok := r.values[reflect.TypeOf(v)]
+	r.mu.RUnlock()
+	if !ok {
+		return v, nil
+	}
+	return fn(v)
+}
+
+// convertedColumn runs a scan conversion and stores the result in dest, a
+// settable field value.
+type convertedColumn struct {
+	column  string
+	convert ScanFunc
+	dest    reflect.Value
+}
+
+func (c *convertedColumn) Scan(src interface{}) error {
+	value, err := c.convert(src)
+	if err != nil {
+		return fmt.Errorf("column %q: %w", c.column, err)
+	}
+	if value == nil {
+		c.dest.SetZero()
+		return nil
+	}
+
+	converted, ok := convertTo(reflect.ValueOf(value), c.dest.Type())
+	if !ok {
+		return fmt.Errorf("column %q: converted %T cannot be stored in %s", c.column, value, c.dest.Type())
+	}
+	c.dest.Set(converted)
+	return nil
+}
+
+// convertTo converts v to typ when no information is lost in the meaning
+// of the value: between types of the same kind, between numbers, between
+// strings and byte slices, and from numbers and bools to their decimal
+// string. Go's integer to string conversion, which yields a rune, is not
+// applied.
+func convertTo(v reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	switch {
+	case v.Type().AssignableTo(typ):
+		return v, true
+	case typ.Kind() == reflect.String && v.Kind() != reflect.String && !isBytes(v.Type()):
+		return formatTo(v, typ)
+	case !v.Type().ConvertibleTo(typ):
+		return reflect.Value{}, false
+	case v.Kind() == typ.Kind(), isNumber(v.Kind()) && isNumber(typ.Kind()):
+		return v.Convert(typ), true
+	case typ.Kind() == reflect.String && isBytes(v.Type()), isBytes(typ) && v.Kind() == reflect.String:
+		return v.Convert(typ), true
+	}
+	return reflect.Value{}, false
+}
+
+// formatTo stores the decimal form of a number or bool in a string typ.
+func formatTo(v reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	var s string
+	switch {
+	case v.CanInt():
+		s = strconv.FormatInt(v.Int(), 10)
+	case v.CanUint():
+		s = strconv.FormatUint(v.Uint(), 10)
+	case v.CanFloat():
+		s = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case v.Kind() == reflect.Bool:
+		s = strconv.FormatBool(v.Bool())
+	default:
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(s).Convert(typ), true
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isBytes(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,109 @@
+package sqlscan
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type cents int64
+
+func TestConvertedColumnScan(t *testing.T) {
+	identity := func(src interface{}) (interface{}, error) { return src, nil }
+	tests := []struct {
+		name string
+		src  interface{}
+		dest interface{}
+		want interface{}
+		err  bool
+	}{
+		{name: "int64 to string", src: int64(65), dest: new(string), want: "65"},
+		{name: "uint to string", src: uint8(7), dest: new(string), want: "7"},
+		{name: "float to string", src: 2.5, dest: new(string), want: "2.5"},
+		{name: "bool to string", src: true, dest: new(string), want: "true"},
+		{name: "bytes to string", src: []byte("abc"), dest: new(string), want: "abc"},
+		{name: "string to bytes", src: "abc", dest: new([]byte), want: []byte("abc")},
+		{name: "int64 to int32", src: int64(7), dest: new(int32), want: int32(7)},
+		{name: "int64 to float", src: int64(7), dest: new(float64), want: float64(7)},
+		{name: "named type", src: int64(250), dest: new(cents), want: cents(250)},
+		{name: "nil", src: nil, dest: new(int64), want: int64(0)},
+		{name: "string to int", src: "65", dest: new(int), err: true},
+		{name: "bool to int", src: true, dest: new(int), err: true},
+	}
+
+	for _, tt := range tests {
+		dest := reflect.ValueOf(tt.dest).Elem()
+		column := &convertedColumn{column: "c", convert: identity, dest: dest}
+		err := column.Scan(tt.src)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: stored %v, want an error", tt.name, dest.Interface())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := dest.Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConvertedColumnScanError(t *testing.T) {
+	errBad := errors.New("bad value")
+	column := &convertedColumn{
+		column:  "price",
+		convert: func(interface{}) (interface{}, error) { return nil, errBad },
+		dest:    reflect.ValueOf(new(cents)).Elem(),
+	}
+	if err := column.Scan("x"); !errors.Is(err, errBad) || !strings.Contains(err.Error(), `"price"`) {
+		t.Errorf("err = %v, want %v for column price", err, errBad)
+	}
+}
+
+func TestConverterRegistry(t *testing.T) {
+	type order struct {
+		ID    int64 `db:"id"`
+		Total cents `db:"total"`
+	}
+
+	registry := NewConverterRegistry()
+	RegisterScanType(registry, func(src interface{}) (cents, error) {
+		f, err := strconv.ParseFloat(string(src.([]byte)), 64)
+		return cents(f*100 + 0.5), err
+	})
+	RegisterValueType(registry, func(v cents) (driver.Value, error) {
+		return strconv.FormatFloat(float64(v)/100, 'f', 2, 64), nil
+	})
+	s := NewScanner(WithConverters(registry))
+
+	var got []order
+	rows := newFakeRows([]string{"id", "total"}, []interface{}{int64(1), []byte("12.34")})
+	if err := s.Rows(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+	if want := []order{{ID: 1, Total: 1234}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rows = %+v, want %+v", got, want)
+	}
+
+	values, err := s.Values([]string{"id", "total"}, &got[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{int64(1), "12.34"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Values = %#v, want %#v", values, want)
+	}
+
+	// A scanner without the registry leaves the field to database/sql.
+	if err := NewScanner(WithConverters(nil)).Rows(&got, newFakeRows([]string{"total"}, []interface{}{int64(5)})); err != nil {
+		t.Fatal(err)
+	}
+	if got[len(got)-1].Total != 5 {
+		t.Errorf("unconverted Total = %v, want 5", got[len(got)-1].Total)
+	}
+}
@@ -0,0 +1,53 @@
+package sqlscan
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrColumnMissing is returned when a struct field that must be scanned has
+// no column in the result set.
+var ErrColumnMissing = errors.New("column missing")
+
+// UnmappedColumnsError lists the result columns that match no field of
+// Type. It is returned by a Scanner using RejectUnknownColumns and matches
+// ErrStructFieldMissing with errors.Is.
+type UnmappedColumnsError struct {
+	Type    reflect.Type
+	Columns []string
+}
+
+func (e *UnmappedColumnsError) Error() string {
+	return fmt.Sprintf("columns %s have no field in %s: %s", quoteNames(e.Columns), e.Type, ErrStructFieldMissing)
+}
+
+func (e *UnmappedColumnsError) Unwrap() error {
+	return ErrStructFieldMissing
+}
+
+// MissingFieldsError lists the columns of Type's fields that were absent
+// from the result set. It is returned by a Scanner using a
+// MissingFieldPolicy other than IgnoreMissingFields and matches
+// ErrColumnMissing with errors.Is.
+type MissingFieldsError struct {
+	Type    reflect.Type
+	Columns []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return fmt.Sprintf("fields of %s have no column %s: %s", e.Type, quoteNames(e.Columns), ErrColumnMissing)
+}
+
+func (e *MissingFieldsError) Unwrap() error {
+	return ErrColumnMissing
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
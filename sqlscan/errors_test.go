@@ -0,0 +1,114 @@
+package sqlscan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestScanErrorModes(t *testing.T) {
+	type enrollment struct {
+		ID     int64  `db:"id"`
+		Grade  string `db:"grade,required"`
+		Status string `db:"status"`
+		Note   string
+	}
+	typ := reflect.TypeOf(enrollment{})
+
+	tests := []struct {
+		name    string
+		scanner *Scanner
+		columns []string
+		want    []enrollment
+		err     error
+	}{
+		{
+			name:    "unknown columns are discarded by default",
+			scanner: NewScanner(),
+			columns: []string{"id", "grade", "secret"},
+			want:    []enrollment{{ID: 1, Grade: "x"}},
+		},
+		{
+			name:    "reject unknown columns",
+			scanner: NewScanner(WithUnknownColumns(RejectUnknownColumns)),
+			columns: []string{"id", "grade", "secret", "other"},
+			err:     &UnmappedColumnsError{Type: typ, Columns: []string{"secret", "other"}},
+		},
+		{
+			name:    "strict rejects columns of untagged fields",
+			scanner: NewScanner(WithStrict(true), WithUnknownColumns(RejectUnknownColumns)),
+			columns: []string{"id", "grade", "note"},
+			err:     &UnmappedColumnsError{Type: typ, Columns: []string{"note"}},
+		},
+		{
+			name:    "missing fields are ignored by default",
+			scanner: NewScanner(),
+			columns: []string{"id", "note"},
+			want:    []enrollment{{ID: 1, Note: "x"}},
+		},
+		{
+			name:    "reject missing required fields",
+			scanner: NewScanner(WithMissingFields(RejectMissingRequiredFields)),
+			columns: []string{"id", "note"},
+			err:     &MissingFieldsError{Type: typ, Columns: []string{"grade"}},
+		},
+		{
+			name:    "required fields present",
+			scanner: NewScanner(WithMissingFields(RejectMissingRequiredFields)),
+			columns: []string{"id", "grade"},
+			want:    []enrollment{{ID: 1, Grade: "x"}},
+		},
+		{
+			name:    "reject missing tagged fields",
+			scanner: NewScanner(WithMissingFields(RejectMissingTaggedFields)),
+			columns: []string{"id", "note"},
+			err:     &MissingFieldsError{Type: typ, Columns: []string{"grade", "status"}},
+		},
+	}
+
+	for _, tt := range tests {
+		values := []interface{}{int64(1)}
+		for range tt.columns[1:] {
+			values = append(values, "x")
+		}
+
+		var got []enrollment
+		err := tt.scanner.Rows(&got, newFakeRows(tt.columns, values))
+		if tt.err != nil {
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("%s: err = %#v, want %#v", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Rows = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanErrorsMatchSentinels(t *testing.T) {
+	typ := reflect.TypeOf(struct{}{})
+	unmapped := error(&UnmappedColumnsError{Type: typ, Columns: []string{"a", "b"}})
+	if !errors.Is(unmapped, ErrStructFieldMissing) {
+		t.Errorf("%v does not match ErrStructFieldMissing", unmapped)
+	}
+	if want := `columns "a", "b" have no field in struct {}: ` + ErrStructFieldMissing.Error(); unmapped.Error() != want {
+		t.Errorf("Error() = %q, want %q", unmapped.Error(), want)
+	}
+
+	missing := error(&MissingFieldsError{Type: typ, Columns: []string{"grade"}})
+	if !errors.Is(missing, ErrColumnMissing) {
+		t.Errorf("%v does not match ErrColumnMissing", missing)
+	}
+	if want := `fields of struct {} have no column "grade": column missing`; missing.Error() != want {
+		t.Errorf("Error() = %q, want %q", missing.Error(), want)
+	}
+
+	var target *MissingFieldsError
+	if !errors.As(missing, &target) || target.Columns[0] != "grade" {
+		t.Errorf("errors.As = %v", target)
+	}
+}
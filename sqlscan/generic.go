@@ -0,0 +1,73 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"iter"
+	"reflect"
+)
+
+// All scans every row into a []T with the package defaults. T is a struct
+// or, for single column results, a primitive type.
+func All[T any](r RowsScanner) ([]T, error) {
+	return AllWith[T](defaultScanner(false), r)
+}
+
+// One scans the first row into a T with the package defaults. It returns
+// sql.ErrNoRows when the result set is empty.
+func One[T any](r RowsScanner) (T, error) {
+	return OneWith[T](defaultScanner(false), r)
+}
+
+// Iter streams the rows as T values with the package defaults, without
+// holding the whole result set in memory. A scan error is yielded once, as
+// the last element. Rows are closed when the iteration ends if auto-close
+// is on.
+func Iter[T any](r RowsScanner) iter.Seq2[T, error] {
+	return IterWith[T](defaultScanner(false), r)
+}
+
+// AllWith is All using scanner s.
+func AllWith[T any](s *Scanner, r RowsScanner) ([]T, error) {
+	var items []T
+	for item, err := range IterWith[T](s, r) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// OneWith is One using scanner s.
+func OneWith[T any](s *Scanner, r RowsScanner) (T, error) {
+	for item, err := range IterWith[T](s, r) {
+		return item, err
+	}
+	var zero T
+	return zero, sql.ErrNoRows
+}
+
+// IterWith is Iter using scanner s.
+func IterWith[T any](s *Scanner, r RowsScanner) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if s.autoClose {
+			defer s.closeRows(r)
+		}
+
+		var item T
+		value := reflect.ValueOf(&item).Elem()
+		next := func() reflect.Value {
+			value.SetZero()
+			return value
+		}
+		stopped := false
+		err := s.scanEach(value.Type(), r, next, func() bool {
+			stopped = !yield(item, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
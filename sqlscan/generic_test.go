@@ -0,0 +1,115 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type genericCourse struct {
+	ID    int64  `db:"id"`
+	Title string `db:"title"`
+}
+
+func courseRows() *fakeRows {
+	return newFakeRows([]string{"id", "title"},
+		[]interface{}{int64(1), "Algebra"},
+		[]interface{}{int64(2), "Biology"},
+		[]interface{}{int64(3), "Chemistry"},
+	)
+}
+
+func TestAll(t *testing.T) {
+	rows := courseRows()
+	got, err := All[genericCourse](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []genericCourse{{1, "Algebra"}, {2, "Biology"}, {3, "Chemistry"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All = %+v, want %+v", got, want)
+	}
+	if !rows.closed {
+		t.Error("rows not closed")
+	}
+
+	ids, err := All[int64](newFakeRows([]string{"id"}, []interface{}{int64(4)}, []interface{}{int64(5)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{4, 5}) {
+		t.Errorf("All[int64] = %v", ids)
+	}
+
+	empty, err := All[genericCourse](newFakeRows([]string{"id", "title"}))
+	if err != nil || empty != nil {
+		t.Errorf("All of no rows = %v, %v, want nil, nil", empty, err)
+	}
+}
+
+func TestOne(t *testing.T) {
+	rows := courseRows()
+	got, err := One[genericCourse](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (genericCourse{1, "Algebra"}) {
+		t.Errorf("One = %+v", got)
+	}
+	if !rows.closed {
+		t.Error("rows not closed")
+	}
+
+	if _, err := One[genericCourse](newFakeRows([]string{"id", "title"})); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("One of no rows: err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestIter(t *testing.T) {
+	rows := courseRows()
+	var titles []string
+	for course, err := range Iter[genericCourse](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, course.Title)
+		if course.ID == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(titles, []string{"Algebra", "Biology"}) {
+		t.Errorf("titles = %v", titles)
+	}
+	if !rows.closed || rows.current != 1 {
+		t.Errorf("rows closed = %v at row %d, want closed at row 1", rows.closed, rows.current)
+	}
+
+	// Without auto-close the caller keeps the rows open.
+	rows = courseRows()
+	for range IterWith[genericCourse](NewScanner(WithAutoClose(false)), rows) {
+	}
+	if rows.closed {
+		t.Error("rows closed without auto-close")
+	}
+}
+
+func TestIterYieldsErrorOnce(t *testing.T) {
+	strict := NewScanner(WithUnknownColumns(RejectUnknownColumns))
+	rows := newFakeRows([]string{"id", "secret"}, []interface{}{int64(1), "x"}, []interface{}{int64(2), "y"})
+
+	var errs []error
+	for course, err := range IterWith[genericCourse](strict, rows) {
+		if course != (genericCourse{}) {
+			t.Errorf("course = %+v, want the zero value", course)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrStructFieldMissing) {
+		t.Errorf("errs = %v, want a single ErrStructFieldMissing", errs)
+	}
+
+	if got, err := AllWith[genericCourse](strict, newFakeRows([]string{"id", "secret"}, []interface{}{int64(1), "x"})); err == nil || got != nil {
+		t.Errorf("AllWith = %v, %v, want an error", got, err)
+	}
+}
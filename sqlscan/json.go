@@ -0,0 +1,47 @@
+package sqlscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// jsonColumn decodes a JSON column into dest, a settable field value.
+type jsonColumn struct {
+	column string
+	dest   reflect.Value
+}
+
+func (c *jsonColumn) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		c.dest.SetZero()
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("column %q: cannot decode %T as JSON", c.column, src)
+	}
+
+	if err := json.Unmarshal(data, c.dest.Addr().Interface()); err != nil {
+		return fmt.Errorf("column %q: invalid JSON: %w", c.column, err)
+	}
+	return nil
+}
+
+// EncodeJSON encodes the value of a field tagged json for a query argument.
+// It returns a string, since MySQL rejects JSON sent with the binary
+// character set of a []byte argument, or nil for a nil value or pointer.
+func EncodeJSON(column string, value interface{}) (interface{}, error) {
+	if v := reflect.ValueOf(value); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("column %q: cannot encode JSON: %w", column, err)
+	}
+	return string(data), nil
+}
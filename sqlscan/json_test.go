@@ -0,0 +1,57 @@
+package sqlscan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONColumnRoundTrip(t *testing.T) {
+	type rule struct {
+		Name   string `json:"name"`
+		Points int    `json:"points"`
+	}
+	type assignment struct {
+		ID     int64             `db:"id"`
+		Rubric []rule            `db:"rubric,json"`
+		Meta   map[string]string `db:"meta,json"`
+		Nested *rule             `db:"nested,json"`
+	}
+	in := assignment{
+		ID:     1,
+		Rubric: []rule{{Name: "clarity", Points: 3}},
+		Meta:   map[string]string{"term": "fall"},
+	}
+
+	columns, err := Columns(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "rubric", "meta", "nested"}; !reflect.DeepEqual(columns, want) {
+		t.Fatalf("Columns = %q, want %q", columns, want)
+	}
+
+	values, err := Values(columns, &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(1), `[{"name":"clarity","points":3}]`, `{"term":"fall"}`, nil}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("Values = %#v, want %#v", values, want)
+	}
+
+	// The driver returns JSON columns as []byte.
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			v = []byte(s)
+		}
+		row[i] = v
+	}
+	var out []assignment
+	if err := NewScanner().Rows(&out, newFakeRows(columns, row)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, []assignment{in}) {
+		t.Errorf("Rows = %+v, want %+v", out, []assignment{in})
+	}
+}
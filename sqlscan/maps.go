@@ -0,0 +1,165 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mysqlTimeLayouts are the text protocol formats of DATE, DATETIME and
+// TIMESTAMP values.
+var mysqlTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Maps scans every row into a map keyed by column name, for result sets
+// without a Go struct. Values are converted according to the column's
+// database type: integers to int64 (uint64 when unsigned), FLOAT and DOUBLE
+// to float64, DATE, DATETIME and TIMESTAMP to time.Time, JSON to its decoded
+// form, binary types to []byte and everything else, including DECIMAL to
+// keep its precision, to string. NULL is nil. Column conversions registered
+// with Converters replace the built-in ones.
+func Maps(r RowsScanner) ([]map[string]interface{}, error) {
+	return defaultScanner(false).Maps(r)
+}
+
+// RowMap scans the first row into a map as described in Maps. It returns
+// sql.ErrNoRows when the result set is empty.
+func RowMap(r RowsScanner) (map[string]interface{}, error) {
+	return defaultScanner(false).RowMap(r)
+}
+
+// Maps is the package function Maps using s.
+func (s *Scanner) Maps(r RowsScanner) ([]map[string]interface{}, error) {
+	if s.autoClose {
+		defer s.closeRows(r)
+	}
+
+	var results []map[string]interface{}
+	err := s.scanMaps(r, func(m map[string]interface{}) bool {
+		results = append(results, m)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RowMap is the package function RowMap using s.
+func (s *Scanner) RowMap(r RowsScanner) (map[string]interface{}, error) {
+	if s.autoClose {
+		defer s.closeRows(r)
+	}
+
+	var result map[string]interface{}
+	err := s.scanMaps(r, func(m map[string]interface{}) bool {
+		result = m
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, sql.ErrNoRows
+	}
+	return result, nil
+}
+
+func (s *Scanner) scanMaps(r RowsScanner, yield func(map[string]interface{}) bool) error {
+	cols, err := r.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	converts := make([]ScanFunc, len(cols))
+	for i := range cols {
+		var typeName string
+		if i < len(types) && types[i] != nil {
+			typeName = types[i].DatabaseTypeName()
+		}
+		converts[i] = s.converters.columnFunc(typeName)
+		if converts[i] == nil {
+			converts[i] = func(src interface{}) (interface{}, error) {
+				return convertColumn(typeName, src)
+			}
+		}
+	}
+
+	values := make([]interface{}, len(cols))
+	pointers := make([]interface{}, len(cols))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for r.Next() {
+		if err := r.Scan(pointers...); err != nil {
+			return err
+		}
+
+		m := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			value, err := converts[i](values[i])
+			if err != nil {
+				return fmt.Errorf("column %q: %w", col, err)
+			}
+			m[col] = value
+		}
+		if !yield(m) {
+			return nil
+		}
+	}
+	return r.Err()
+}
+
+// convertColumn converts a driver value to the Go type matching the
+// database type name reported by the driver. Values the driver already
+// converted, e.g. through the binary protocol or parseTime, are kept.
+func convertColumn(typeName string, value interface{}) (interface{}, error) {
+	b, ok := value.([]byte)
+	if !ok {
+		return value, nil
+	}
+
+	typeName = strings.ToUpper(typeName)
+	switch strings.TrimPrefix(typeName, "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR":
+		if strings.HasPrefix(typeName, "UNSIGNED ") {
+			return strconv.ParseUint(string(b), 10, 64)
+		}
+		return strconv.ParseInt(string(b), 10, 64)
+	case "FLOAT", "DOUBLE", "REAL":
+		return strconv.ParseFloat(string(b), 64)
+	case "DATE", "DATETIME", "TIMESTAMP":
+		return parseTime(string(b))
+	case "JSON":
+		var decoded interface{}
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		return b, nil
+	}
+	return string(b), nil
+}
+
+func parseTime(value string) (interface{}, error) {
+	if strings.HasPrefix(value, "0000-00-00") {
+		return time.Time{}, nil
+	}
+	for _, layout := range mysqlTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q", value)
+}
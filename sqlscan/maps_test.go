@@ -0,0 +1,111 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConvertColumn(t *testing.T) {
+	tests := []struct {
+		typeName string
+		src      interface{}
+		want     interface{}
+		err      bool
+	}{
+		{typeName: "INT", src: []byte("-42"), want: int64(-42)},
+		{typeName: "BIGINT", src: []byte("9007199254740993"), want: int64(9007199254740993)},
+		{typeName: "UNSIGNED BIGINT", src: []byte("18446744073709551615"), want: uint64(18446744073709551615)},
+		{typeName: "tinyint", src: []byte("1"), want: int64(1)},
+		{typeName: "DOUBLE", src: []byte("2.5"), want: 2.5},
+		{typeName: "DECIMAL", src: []byte("0.10"), want: "0.10"},
+		{typeName: "DATETIME", src: []byte("2024-05-01 12:30:00.5"), want: time.Date(2024, 5, 1, 12, 30, 0, 5e8, time.UTC)},
+		{typeName: "DATE", src: []byte("2024-05-01"), want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{typeName: "TIMESTAMP", src: []byte("0000-00-00 00:00:00"), want: time.Time{}},
+		{typeName: "JSON", src: []byte(`{"a":[1,"b"]}`), want: map[string]interface{}{"a": []interface{}{1.0, "b"}}},
+		{typeName: "VARBINARY", src: []byte{0, 1}, want: []byte{0, 1}},
+		{typeName: "VARCHAR", src: []byte("abc"), want: "abc"},
+		{typeName: "", src: []byte("abc"), want: "abc"},
+		{typeName: "INT", src: int64(7), want: int64(7)},
+		{typeName: "INT", src: nil, want: nil},
+		{typeName: "INT", src: []byte("x"), err: true},
+		{typeName: "DATETIME", src: []byte("yesterday"), err: true},
+		{typeName: "JSON", src: []byte("{"), err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := convertColumn(tt.typeName, tt.src)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s %q: err = nil, want an error", tt.typeName, tt.src)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.typeName, tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q = %#v, want %#v", tt.typeName, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestMaps(t *testing.T) {
+	rows := newFakeRows([]string{"id", "title", "grade"},
+		[]interface{}{int64(1), []byte("Algebra"), nil},
+		[]interface{}{int64(2), []byte("Biology"), []byte("A")},
+	)
+	got, err := Maps(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{
+		{"id": int64(1), "title": "Algebra", "grade": nil},
+		{"id": int64(2), "title": "Biology", "grade": "A"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Maps = %v, want %v", got, want)
+	}
+	if !rows.closed {
+		t.Error("rows not closed")
+	}
+
+	row, err := RowMap(newFakeRows([]string{"id"}, []interface{}{int64(3)}, []interface{}{int64(4)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(row, map[string]interface{}{"id": int64(3)}) {
+		t.Errorf("RowMap = %v", row)
+	}
+	if _, err := RowMap(newFakeRows([]string{"id"})); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("RowMap of no rows: err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMapsColumnConverter(t *testing.T) {
+	// fakeRows reports no column types, so the conversion is registered for
+	// the empty type name.
+	registry := NewConverterRegistry()
+	registry.RegisterColumn("", func(src interface{}) (interface{}, error) {
+		if b, ok := src.([]byte); ok {
+			return len(b), nil
+		}
+		return nil, errors.New("not bytes")
+	})
+	s := NewScanner(WithConverters(registry))
+
+	got, err := s.Maps(newFakeRows([]string{"title"}, []interface{}{[]byte("Algebra")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []map[string]interface{}{{"title": 7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Maps = %v, want %v", got, want)
+	}
+
+	if _, err := s.Maps(newFakeRows([]string{"title"}, []interface{}{int64(1)})); err == nil {
+		t.Error("converter error was not returned")
+	}
+}
@@ -0,0 +1,159 @@
+package sqlscan
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Naming converts between Go field names and column names. Field is used
+// by Rows and Row to find the field of an untagged column, Column by Columns
+// to name an untagged field; the two should be inverses of each other.
+type Naming struct {
+	Field  func(column string) string
+	Column func(field string) string
+}
+
+// SnakeCaseNaming maps `created_at` columns to `CreatedAt` fields and back.
+// The round trip is lossy for acronyms: `UserID` is stored as `user_id`,
+// which maps back to `UserId`. Use AcronymNaming to keep them.
+var SnakeCaseNaming = Naming{Field: PascalCase, Column: SnakeCase}
+
+// AcronymNaming is SnakeCaseNaming keeping acronyms upper-cased in field
+// names, so that `lms_course_id` maps to `LMSCourseID` and back:
+//
+//	sqlscan.AcronymNaming("ID", "URL", "LMS")
+func AcronymNaming(acronyms ...string) Naming {
+	known := make(map[string]struct{}, len(acronyms))
+	for _, acronym := range acronyms {
+		known[strings.ToUpper(acronym)] = struct{}{}
+	}
+
+	return Naming{
+		Field: func(column string) string {
+			return pascalCase(splitWords(column, known), known)
+		},
+		Column: func(field string) string {
+			return snakeCase(splitWords(field, known))
+		},
+	}
+}
+
+// WithNaming sets both the field and the column mapper of a Scanner.
+func WithNaming(naming Naming) Option {
+	return func(s *Scanner) {
+		s.mapper = naming.Field
+		s.columnsMapper = naming.Column
+	}
+}
+
+// SnakeCase converts a Go field name to a snake_case column name, treating
+// runs of capitals as one word: `CourseURL` becomes `course_url`.
+func SnakeCase(name string) string {
+	return snakeCase(splitWords(name, nil))
+}
+
+// PascalCase converts a snake_case column name to an exported Go field name:
+// `created_at` becomes `CreatedAt`. It knows no acronyms, so it is not the
+// inverse of SnakeCase for them: `user_id` becomes `UserId`, not `UserID`.
+func PascalCase(name string) string {
+	return pascalCase(splitWords(name, nil), nil)
+}
+
+// titleCase is the default field mapper. It title-cases the column name as
+// a whole, so `title` becomes `Title` but `created_at` becomes `Created_at`;
+// configure a Scanner with SnakeCaseNaming to map snake_case columns.
+func titleCase(name string) string {
+	return cases.Title(language.English).String(name)
+}
+
+func snakeCase(words []string) string {
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+func pascalCase(words []string, acronyms map[string]struct{}) string {
+	var sb strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if _, ok := acronyms[upper]; ok {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// splitWords splits name on underscores, dashes and case changes. A run of
+// capitals is one word, except that its last capital starts the next word
+// when followed by a lower case letter (`URLPath` is `URL`, `Path`), and
+// that known acronyms at the start of a run are split off (`LMSID` is
+// `LMS`, `ID`).
+func splitWords(name string, acronyms map[string]struct{}) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, splitAcronyms(string(runes[start:end]), acronyms)...)
+		}
+		start = -1
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+// splitAcronyms splits an all capitals word into the known acronyms it
+// starts with.
+func splitAcronyms(word string, acronyms map[string]struct{}) []string {
+	if len(acronyms) == 0 || strings.ToUpper(word) != word {
+		return []string{word}
+	}
+
+	var words []string
+	for len(word) > 0 {
+		match := ""
+		for acronym := range acronyms {
+			if len(acronym) > len(match) && strings.HasPrefix(word, acronym) {
+				match = acronym
+			}
+		}
+		if match == "" {
+			break
+		}
+		words = append(words, match)
+		word = word[len(match):]
+	}
+	if word != "" {
+		words = append(words, word)
+	}
+	return words
+}
@@ -0,0 +1,65 @@
+package sqlscan
+
+import "testing"
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"ID":          "id",
+		"UserID":      "user_id",
+		"CreatedAt":   "created_at",
+		"CourseURL":   "course_url",
+		"URLPath":     "url_path",
+		"HTTPServer":  "http_server",
+		"LMSID":       "lmsid",
+		"Grade2Score": "grade2_score",
+		"already_set": "already_set",
+	}
+	for in, want := range tests {
+		if got := SnakeCase(in); got != want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := map[string]string{
+		"id":          "Id",
+		"user_id":     "UserId",
+		"created_at":  "CreatedAt",
+		"http_server": "HttpServer",
+		"lms-id":      "LmsId",
+		"Title":       "Title",
+	}
+	for in, want := range tests {
+		if got := PascalCase(in); got != want {
+			t.Errorf("PascalCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// PascalCase knows no acronyms, so it does not undo SnakeCase for them.
+	if got := PascalCase(SnakeCase("UserID")); got != "UserId" {
+		t.Errorf("PascalCase(SnakeCase(%q)) = %q, want %q", "UserID", got, "UserId")
+	}
+}
+
+func TestAcronymNaming(t *testing.T) {
+	naming := AcronymNaming("ID", "LMS", "HTTP", "URL")
+	tests := []struct {
+		field, column string
+	}{
+		{field: "UserID", column: "user_id"},
+		{field: "LMSID", column: "lms_id"},
+		{field: "LMSCourseID", column: "lms_course_id"},
+		{field: "HTTPServer", column: "http_server"},
+		{field: "CourseURL", column: "course_url"},
+		{field: "CreatedAt", column: "created_at"},
+	}
+	for _, tt := range tests {
+		if got := naming.Column(tt.field); got != tt.column {
+			t.Errorf("Column(%q) = %q, want %q", tt.field, got, tt.column)
+		}
+		if got := naming.Field(tt.column); got != tt.field {
+			t.Errorf("Field(%q) = %q, want %q", tt.column, got, tt.field)
+		}
+	}
+}
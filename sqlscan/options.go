@@ -0,0 +1,240 @@
+package sqlscan
+
+import (
+	"reflect"
+	"sync"
+)
+
+// UnknownColumnPolicy decides what happens to result columns that do not
+// match a struct field.
+type UnknownColumnPolicy int
+
+const (
+	// DiscardUnknownColumns scans unmatched columns into a throwaway value.
+	DiscardUnknownColumns UnknownColumnPolicy = iota
+	// RejectUnknownColumns fails the scan with an *UnmappedColumnsError.
+	RejectUnknownColumns
+)
+
+// MissingFieldPolicy decides what happens to struct fields that have no
+// column in the result set.
+type MissingFieldPolicy int
+
+const (
+	// IgnoreMissingFields leaves fields without a column at their zero
+	// value.
+	IgnoreMissingFields MissingFieldPolicy = iota
+	// RejectMissingRequiredFields fails the scan with a
+	// *MissingFieldsError when a field tagged `db:"name,required"` has no
+	// column.
+	RejectMissingRequiredFields
+	// RejectMissingTaggedFields fails the scan with a *MissingFieldsError
+	// when any db tagged field has no column.
+	RejectMissingTaggedFields
+)
+
+// Scanner scans rows with its own configuration, independent of the package
+// variables. A Scanner is safe for concurrent use and caches its scan plans,
+// so it should be created once and shared. The zero value is usable: it maps
+// columns with the default field names, leaves rows open and applies no
+// conversions.
+type Scanner struct {
+	mapper         func(string) string
+	columnsMapper  func(string) string
+	strict         bool
+	autoClose      bool
+	onCloseError   func(error)
+	unknownColumns UnknownColumnPolicy
+	missingFields  MissingFieldPolicy
+	converters     *ConverterRegistry
+
+	plans        cache
+	columnsCache cache
+	initCaches   sync.Once
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// NewScanner returns a Scanner with the same defaults as the package
+// functions: title-cased field names, auto-close, discarded unknown
+// columns and the package Converters.
+func NewScanner(opts ...Option) *Scanner {
+	s := &Scanner{
+		mapper:        titleCase,
+		columnsMapper: func(name string) string { return name },
+		autoClose:     true,
+		onCloseError:  func(error) {},
+		converters:    Converters,
+		plans:         &sync.Map{},
+		columnsCache:  &sync.Map{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithMapper sets the function that maps column names to struct field names
+// for fields without a db tag. A nil mapper restores the default.
+func WithMapper(mapper func(string) string) Option {
+	return func(s *Scanner) { s.mapper = mapper }
+}
+
+// WithColumnsMapper sets the function that maps struct field names to column
+// names in Columns. A nil mapper keeps field names as they are.
+func WithColumnsMapper(mapper func(string) string) Option {
+	return func(s *Scanner) { s.columnsMapper = mapper }
+}
+
+// WithStrict only matches columns to db tagged fields, as RowsStrict does.
+func WithStrict(strict bool) Option {
+	return func(s *Scanner) { s.strict = strict }
+}
+
+// WithAutoClose controls whether rows are closed once scanned.
+func WithAutoClose(autoClose bool) Option {
+	return func(s *Scanner) { s.autoClose = autoClose }
+}
+
+// WithCloseErrorHandler receives the errors returned by rows.Close() when
+// auto-close is on.
+func WithCloseErrorHandler(handler func(error)) Option {
+	return func(s *Scanner) { s.onCloseError = handler }
+}
+
+// WithUnknownColumns sets the policy for columns without a matching field.
+func WithUnknownColumns(policy UnknownColumnPolicy) Option {
+	return func(s *Scanner) { s.unknownColumns = policy }
+}
+
+// WithMissingFields sets the policy for fields without a matching column.
+func WithMissingFields(policy MissingFieldPolicy) Option {
+	return func(s *Scanner) { s.missingFields = policy }
+}
+
+// WithConverters applies the conversions of registry instead of the package
+// Converters.
+func WithConverters(registry *ConverterRegistry) Option {
+	return func(s *Scanner) { s.converters = registry }
+}
+
+// defaultScanner returns a Scanner configured from the package variables.
+// The mappers are the ones snapshotted by loadPackageCaches.
+func defaultScanner(strict bool) *Scanner {
+	caches := loadPackageCaches()
+	return &Scanner{
+		mapper:        caches.mapper,
+		columnsMapper: caches.columnsMapper,
+		strict:        strict,
+		autoClose:     AutoClose,
+		onCloseError:  OnAutoCloseError,
+		converters:    Converters,
+		plans:         caches.plans,
+		columnsCache:  caches.columns,
+	}
+}
+
+// packageCaches are the mappers and caches shared by the package functions.
+type packageCaches struct {
+	mapper        func(string) string
+	columnsMapper func(string) string
+	plans         cache
+	columns       cache
+}
+
+var (
+	packageCachesOnce sync.Once
+	defaultCaches     *packageCaches
+)
+
+// loadPackageCaches snapshots ScannerMapper and ColumnsMapper on first use,
+// so that the cached plans always match the mappers they were built with.
+func loadPackageCaches() *packageCaches {
+	packageCachesOnce.Do(func() {
+		defaultCaches = &packageCaches{
+			mapper:        ScannerMapper,
+			columnsMapper: ColumnsMapper,
+			plans:         &sync.Map{},
+			columns:       &sync.Map{},
+		}
+	})
+	return defaultCaches
+}
+
+// Row scans a single row into v, like the package function Row.
+func (s *Scanner) Row(v interface{}, r RowsScanner) error {
+	if s.autoClose {
+		defer s.closeRows(r)
+	}
+	return s.row(v, r)
+}
+
+// Rows scans rows into the slice pointed to by v, like the package function
+// Rows.
+func (s *Scanner) Rows(v interface{}, r RowsScanner) error {
+	if s.autoClose {
+		defer s.closeRows(r)
+	}
+	return s.rows(v, r)
+}
+
+// Columns returns the column names of the struct pointed to by v, like the
+// package function Columns.
+func (s *Scanner) Columns(v interface{}, excluded ...string) ([]string, error) {
+	return s.columns(v, excluded...)
+}
+
+// fieldName maps an untagged column to the name of its struct field.
+func (s *Scanner) fieldName(column string) string {
+	if s.mapper == nil {
+		return titleCase(column)
+	}
+	return s.mapper(column)
+}
+
+// columnName maps an untagged struct field to the name of its column.
+func (s *Scanner) columnName(field string) string {
+	if s.columnsMapper == nil {
+		return field
+	}
+	return s.columnsMapper(field)
+}
+
+// caches returns the plan and columns caches, creating them on first use
+// for a Scanner that was not built by NewScanner.
+func (s *Scanner) caches() (plans, columns cache) {
+	s.initCaches.Do(func() {
+		if s.plans == nil {
+			s.plans = &sync.Map{}
+		}
+		if s.columnsCache == nil {
+			s.columnsCache = &sync.Map{}
+		}
+	})
+	return s.plans, s.columnsCache
+}
+
+// checkPlan applies the unknown column and missing field policies.
+func (s *Scanner) checkPlan(typ reflect.Type, plan *scanPlan) error {
+	if s.unknownColumns == RejectUnknownColumns && len(plan.unknown) > 0 {
+		return &UnmappedColumnsError{Type: typ, Columns: plan.unknown}
+	}
+
+	var missing []string
+	for _, field := range plan.missing {
+		if s.missingFields == RejectMissingTaggedFields || s.missingFields == RejectMissingRequiredFields && field.Required {
+			missing = append(missing, field.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingFieldsError{Type: typ, Columns: missing}
+	}
+	return nil
+}
+
+func (s *Scanner) closeRows(r RowsScanner) {
+	if err := r.Close(); err != nil && s.onCloseError != nil {
+		s.onCloseError(err)
+	}
+}
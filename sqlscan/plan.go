@@ -0,0 +1,129 @@
+package sqlscan
+
+import (
+	"reflect"
+	"strings"
+)
+
+// planKey identifies a scan plan within the cache of a Scanner, whose
+// mapper is fixed, so the mapper is not part of the key.
+type planKey struct {
+	Type       reflect.Type
+	Columns    string
+	Types      string
+	Strict     bool
+	Converters *ConverterRegistry
+	Version    uint64
+}
+
+// scanPlan holds, for every column of a result set, the index of the struct
+// field it scans into, or nil when the column is discarded. It also records
+// the unmatched columns and the tagged fields without a column.
+type scanPlan struct {
+	fields  []planField
+	unknown []string
+	missing []Field
+}
+
+// planField is the scan target of a column. JSON fields are scanned through
+// a jsonColumn that decodes the column value into the field, and fields with
+// a registered conversion through a convertedColumn.
+type planField struct {
+	index   []int
+	json    bool
+	convert ScanFunc
+}
+
+// loadPlan returns the scan plan for typ and cols, building it on first
+// use. Plans are keyed by the column list and types, strictness and
+// converters, so a query shape is only resolved once no matter how many rows
+// it returns. typeNames is nil when no conversion is registered.
+func (s *Scanner) loadPlan(typ reflect.Type, cols []string, typeNames []string) *scanPlan {
+	key := planKey{
+		Type:       typ,
+		Columns:    strings.Join(cols, "\x00"),
+		Types:      strings.Join(typeNames, "\x00"),
+		Strict:     s.strict,
+		Converters: s.converters,
+	}
+	if s.converters != nil {
+		key.Version = s.converters.version.Load()
+	}
+	plans, _ := s.caches()
+	if cached, ok := plans.Load(key); ok {
+		return cached.(*scanPlan)
+	}
+
+	plan := s.buildPlan(typ, cols, typeNames)
+	plans.Store(key, plan)
+	return plan
+}
+
+func (s *Scanner) buildPlan(typ reflect.Type, cols []string, typeNames []string) *scanPlan {
+	item := reflect.New(typ).Elem()
+	tagged := make(map[string]planField, len(cols))
+	fieldIndexes(typ, nil, "", tagged)
+
+	plan := &scanPlan{fields: make([]planField, len(cols))}
+	for i, colName := range cols {
+		field, ok := tagged[colName]
+		if !ok && !s.strict {
+			if structField, found := typ.FieldByName(s.fieldName(colName)); found {
+				field = planField{index: structField.Index}
+			}
+		}
+		if field.index != nil && item.FieldByIndex(field.index).CanSet() {
+			if typeNames != nil && !field.json {
+				field.convert = s.converters.scanFunc(typ.FieldByIndex(field.index).Type, typeNames[i])
+			}
+			plan.fields[i] = field
+			continue
+		}
+		plan.unknown = append(plan.unknown, colName)
+	}
+
+	present := make(map[string]struct{}, len(cols))
+	for _, colName := range cols {
+		present[colName] = struct{}{}
+	}
+	for _, field := range TaggedFields(typ) {
+		if _, ok := present[field.Name]; !ok {
+			plan.missing = append(plan.missing, field)
+		}
+	}
+	return plan
+}
+
+// fieldIndexes records the scan target of every db tag of typ. Fields of
+// nested structs are registered under the prefix returned by NestedPrefix.
+func fieldIndexes(typ reflect.Type, index []int, prefix string, m map[string]planField) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		if nested, ok := NestedPrefix(field); ok {
+			fieldIndexes(field.Type, fieldIndex, prefix+nested, m)
+			continue
+		}
+		tag, ok := field.Tag.Lookup(dbTag)
+		if name, opts := ParseTag(tag); ok && name != "" {
+			m[prefix+name] = planField{index: fieldIndex, json: opts.JSON}
+		}
+	}
+}
+
+// pointers fills dest with the scan destinations of item. Discarded columns
+// scan into discard.
+func (p *scanPlan) pointers(item reflect.Value, dest []interface{}, cols []string, discard *interface{}) {
+	for i, field := range p.fields {
+		switch {
+		case field.index == nil:
+			dest[i] = discard
+		case field.json:
+			dest[i] = &jsonColumn{column: cols[i], dest: item.FieldByIndex(field.index)}
+		case field.convert != nil:
+			dest[i] = &convertedColumn{column: cols[i], convert: field.convert, dest: item.FieldByIndex(field.index)}
+		default:
+			dest[i] = item.FieldByIndex(field.index).Addr().Interface()
+		}
+	}
+}
@@ -4,11 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"io"
 	"reflect"
-
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 var (
@@ -28,9 +24,13 @@ var (
 	// By default this is a NOOP function
 	OnAutoCloseError = func(error) {}
 
-	// ScannerMapper transforms database field names into struct/map field names
-	// E.g. you can set function for convert snake_case into CamelCase
-	ScannerMapper = func(name string) string { return cases.Title(language.English).String(name) }
+	// ScannerMapper transforms database field names into struct/map field names.
+	// By default it title-cases them, which only matches single word columns.
+	//
+	// Deprecated: ScannerMapper is read once, by the first package function
+	// that scans, and later assignments are ignored. Use a Scanner configured
+	// with WithMapper or WithNaming instead.
+	ScannerMapper = titleCase
 )
 
 // Row scans a single row into a single variable. It requires that you use
@@ -39,24 +39,16 @@ var (
 // defers returning err until Scan is called, which is an unnecessary
 // optimization for this library.
 func Row(v interface{}, r RowsScanner) error {
-	if AutoClose {
-		defer closeRows(r)
-	}
-
-	return row(v, r, false)
+	return defaultScanner(false).Row(v, r)
 }
 
 // RowStrict scans a single row into a single variable. It is identical to
 // Row, but it ignores fields that do not have a db tag
 func RowStrict(v interface{}, r RowsScanner) error {
-	if AutoClose {
-		defer closeRows(r)
-	}
-
-	return row(v, r, true)
+	return defaultScanner(true).Row(v, r)
 }
 
-func row(v interface{}, r RowsScanner, strict bool) error {
+func (s *Scanner) row(v interface{}, r RowsScanner) error {
 	vType := reflect.TypeOf(v)
 	if k := vType.Kind(); k != reflect.Ptr {
 		return fmt.Errorf("%q must be a pointer: %w", k.String(), ErrNotAPointer)
@@ -69,7 +61,7 @@ func row(v interface{}, r RowsScanner, strict bool) error {
 	}
 
 	sl := reflect.New(reflect.SliceOf(vType))
-	err := rows(sl.Interface(), r, strict)
+	err := s.rows(sl.Interface(), r)
 	if err != nil {
 		return err
 	}
@@ -87,23 +79,15 @@ func row(v interface{}, r RowsScanner, strict bool) error {
 
 // Rows scans sql rows into a slice (v)
 func Rows(v interface{}, r RowsScanner) (outerr error) {
-	if AutoClose {
-		defer closeRows(r)
-	}
-
-	return rows(v, r, false)
+	return defaultScanner(false).Rows(v, r)
 }
 
 // RowsStrict scans sql rows into a slice (v) only using db tags
 func RowsStrict(v interface{}, r RowsScanner) (outerr error) {
-	if AutoClose {
-		defer closeRows(r)
-	}
-
-	return rows(v, r, true)
+	return defaultScanner(true).Rows(v, r)
 }
 
-func rows(v interface{}, r RowsScanner, strict bool) (outerr error) {
+func (s *Scanner) rows(v interface{}, r RowsScanner) (outerr error) {
 	vType := reflect.TypeOf(v)
 	if k := vType.Kind(); k != reflect.Ptr {
 		return fmt.Errorf("%q must be a pointer: %w", k.String(), ErrNotAPointer)
@@ -116,89 +100,76 @@ func rows(v interface{}, r RowsScanner, strict bool) (outerr error) {
 	sliceVal := reflect.Indirect(reflect.ValueOf(v))
 	itemType := sliceType.Elem()
 
+	var sliceItem reflect.Value
+	next := func() reflect.Value {
+		sliceItem = reflect.New(itemType).Elem()
+		return sliceItem
+	}
+	return s.scanEach(itemType, r, next, func() bool {
+		sliceVal.Set(reflect.Append(sliceVal, sliceItem))
+		return true
+	})
+}
+
+// scanEach scans every row of r into the addressable itemType value returned
+// by next and calls yield once the row is scanned. It stops early when yield
+// returns false.
+func (s *Scanner) scanEach(itemType reflect.Type, r RowsScanner, next func() reflect.Value, yield func() bool) error {
 	cols, err := r.Columns()
 	if err != nil {
 		return err
 	}
 
 	isPrimitive := itemType.Kind() != reflect.Struct
+	if len(cols) == 0 {
+		return nil
+	}
+
+	// The plan and the pointer slice are shared by every row; Scan copies
+	// the values out before the next row reuses them. Discarded columns
+	// scan into a single throwaway value.
+	var plan *scanPlan
+	if !isPrimitive {
+		var typeNames []string
+		if !s.converters.empty() {
+			types, err := r.ColumnTypes()
+			if err != nil {
+				return err
+			}
+			typeNames = make([]string, len(cols))
+			for i, ct := range types {
+				if i < len(cols) && ct != nil {
+					typeNames[i] = ct.DatabaseTypeName()
+				}
+			}
+		}
+		plan = s.loadPlan(itemType, cols, typeNames)
+		if err := s.checkPlan(itemType, plan); err != nil {
+			return err
+		}
+	}
+	pointers := make([]interface{}, len(cols))
+	var nothing interface{}
 
 	for r.Next() {
-		sliceItem := reflect.New(itemType).Elem()
+		item := next()
 
-		var pointers []interface{}
 		if isPrimitive {
 			if len(cols) > 1 {
 				return ErrTooManyColumns
 			}
-			pointers = []interface{}{sliceItem.Addr().Interface()}
+			pointers[0] = item.Addr().Interface()
 		} else {
-			pointers = structPointers(sliceItem, cols, strict)
-		}
-
-		if len(pointers) == 0 {
-			return nil
+			plan.pointers(item, pointers, cols, &nothing)
 		}
 
 		err := r.Scan(pointers...)
 		if err != nil {
 			return err
 		}
-		sliceVal.Set(reflect.Append(sliceVal, sliceItem))
-	}
-	return r.Err()
-}
-
-// Initialization the tags from struct.
-func initFieldTag(sliceItem reflect.Value, fieldTagMap *map[string]reflect.Value) {
-	typ := sliceItem.Type()
-	for i := 0; i < sliceItem.NumField(); i++ {
-		if typ.Field(i).Anonymous || typ.Field(i).Type.Kind() == reflect.Struct {
-			// found an embedded struct
-			sliceItemOfAnonymous := sliceItem.Field(i)
-			initFieldTag(sliceItemOfAnonymous, fieldTagMap)
-		}
-		tag, ok := typ.Field(i).Tag.Lookup("db")
-		if ok && tag != "" {
-			(*fieldTagMap)[tag] = sliceItem.Field(i)
-		}
-	}
-}
-
-func structPointers(sliceItem reflect.Value, cols []string, strict bool) []interface{} {
-	pointers := make([]interface{}, 0, len(cols))
-	fieldTag := make(map[string]reflect.Value, len(cols))
-	initFieldTag(sliceItem, &fieldTag)
-
-	for _, colName := range cols {
-		var fieldVal reflect.Value
-		if v, ok := fieldTag[colName]; ok {
-			fieldVal = v
-		} else {
-			if strict {
-				fieldVal = reflect.ValueOf(nil)
-			} else {
-				fieldVal = sliceItem.FieldByName(ScannerMapper(colName))
-			}
-		}
-		if !fieldVal.IsValid() || !fieldVal.CanSet() {
-			// have to add if we found a column because Scan() requires
-			// len(cols) arguments or it will error. This way we can scan to
-			// a useless pointer
-			var nothing interface{}
-			pointers = append(pointers, &nothing)
-			continue
-		}
-
-		pointers = append(pointers, fieldVal.Addr().Interface())
-	}
-	return pointers
-}
-
-func closeRows(c io.Closer) {
-	if err := c.Close(); err != nil {
-		if OnAutoCloseError != nil {
-			OnAutoCloseError(err)
+		if !yield() {
+			return nil
 		}
 	}
+	return r.Err()
 }
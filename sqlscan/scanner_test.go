@@ -0,0 +1,155 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeRows is an in-memory RowsScanner. Scan converts each value to the
+// type of its destination, or passes it to the destination's Scan method.
+type fakeRows struct {
+	columns []string
+	values  [][]interface{}
+	current int
+	closed  bool
+}
+
+func newFakeRows(columns []string, values ...[]interface{}) *fakeRows {
+	return &fakeRows{columns: columns, values: values, current: -1}
+}
+
+func (r *fakeRows) Close() error                            { r.closed = true; return nil }
+func (r *fakeRows) Columns() ([]string, error)              { return r.columns, nil }
+func (r *fakeRows) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+func (r *fakeRows) Err() error                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.current++
+	return r.current < len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	row := r.values[r.current]
+	if len(dest) != len(row) {
+		return fmt.Errorf("scan: %d destinations for %d columns", len(dest), len(row))
+	}
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(row[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		target := reflect.ValueOf(d).Elem()
+		if row[i] == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		target.Set(reflect.ValueOf(row[i]).Convert(target.Type()))
+	}
+	return nil
+}
+
+func TestScannersKeepTheirOwnPlans(t *testing.T) {
+	type course struct {
+		LmsId string
+		LMSID string
+	}
+	// Both mappers are closures created from the same function literal.
+	plain := NewScanner(WithNaming(AcronymNaming()))
+	acronyms := NewScanner(WithNaming(AcronymNaming("LMS", "ID")))
+
+	for i := 0; i < 2; i++ {
+		var a, b []course
+		if err := plain.Rows(&a, newFakeRows([]string{"lms_id"}, []interface{}{"x"})); err != nil {
+			t.Fatal(err)
+		}
+		if err := acronyms.Rows(&b, newFakeRows([]string{"lms_id"}, []interface{}{"x"})); err != nil {
+			t.Fatal(err)
+		}
+		if a[0].LmsId != "x" || b[0].LMSID != "x" {
+			t.Errorf("got %+v and %+v", a[0], b[0])
+		}
+	}
+}
+
+func BenchmarkRows(b *testing.B) {
+	type enrollment struct {
+		ID        int64  `db:"id"`
+		CourseID  int64  `db:"course_id"`
+		StudentID int64  `db:"student_id"`
+		Status    string `db:"status"`
+		Grade     string `db:"grade"`
+	}
+	columns := []string{"id", "course_id", "student_id", "status", "grade"}
+	values := make([][]interface{}, 100)
+	for i := range values {
+		values[i] = []interface{}{int64(i), int64(7), int64(1000 + i), "active", "A"}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var got []enrollment
+		if err := Rows(&got, newFakeRows(columns, values...)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestZeroValueScanner(t *testing.T) {
+	type course struct {
+		ID    int64 `db:"id"`
+		Title string
+	}
+
+	scanners := map[string]*Scanner{
+		"zero value":  {},
+		"nil mappers": NewScanner(WithMapper(nil), WithColumnsMapper(nil)),
+	}
+	for name, s := range scanners {
+		var got []course
+		rows := newFakeRows([]string{"id", "title"}, []interface{}{int64(1), "Algebra"})
+		if err := s.Rows(&got, rows); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if want := []course{{ID: 1, Title: "Algebra"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Rows = %+v, want %+v", name, got, want)
+		}
+
+		columns, err := s.Columns(&course{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if want := []string{"id", "Title"}; !reflect.DeepEqual(columns, want) {
+			t.Errorf("%s: Columns = %q, want %q", name, columns, want)
+		}
+	}
+}
+
+func TestDefaultMapperTitleCases(t *testing.T) {
+	type course struct {
+		Title      string
+		CreatedAt  string
+		Created_at string
+	}
+	columns := []string{"title", "created_at"}
+
+	var got []course
+	if err := NewScanner().Rows(&got, newFakeRows(columns, []interface{}{"a", "b"})); err != nil {
+		t.Fatal(err)
+	}
+	if want := []course{{Title: "a", Created_at: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default: Rows = %+v, want %+v", got, want)
+	}
+
+	got = nil
+	if err := NewScanner(WithNaming(SnakeCaseNaming)).Rows(&got, newFakeRows(columns, []interface{}{"a", "b"})); err != nil {
+		t.Fatal(err)
+	}
+	if want := []course{{Title: "a", CreatedAt: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("snake case: Rows = %+v, want %+v", got, want)
+	}
+}
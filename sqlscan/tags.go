@@ -0,0 +1,166 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagOptions are the options that follow the column name in a db struct tag,
+// e.g. `db:"created_at,readonly"`. Most are used by query builders to
+// decide which fields are written; Required is checked when scanning.
+type TagOptions struct {
+	// OmitEmpty skips the field when it holds "", 0, false, nil or an empty
+	// slice or map.
+	OmitEmpty bool
+	// OmitZero skips the field when it is zero, using its IsZero method
+	// when it has one (e.g. time.Time).
+	OmitZero bool
+	// ReadOnly fields are never inserted or updated.
+	ReadOnly bool
+	// AutoIncrement fields are only inserted when non-zero and never
+	// updated.
+	AutoIncrement bool
+	// InsertOnly fields are inserted but never updated.
+	InsertOnly bool
+	// Default fields are left out of inserts when zero so that the column
+	// default applies.
+	Default bool
+	// JSON fields hold a JSON column: scanning decodes it into the field
+	// and query builders encode the field, as in `db:"rubric,json"`.
+	JSON bool
+	// Required fields must have a column in the result set when the
+	// Scanner uses RejectMissingRequiredFields.
+	Required bool
+	// Prefix is prepended to the column names of a nested struct's fields,
+	// as in `db:"guardian,prefix=guardian_"`.
+	Prefix string
+	// Dotted prefixes the column names of a nested struct's fields with the
+	// tag name and a dot, as in `db:"guardian,dotted"`.
+	Dotted bool
+}
+
+// Field is a db tagged struct field.
+type Field struct {
+	// Name is the column name, the tag without its options.
+	Name string
+	// Index is the field index sequence for reflect.Value.FieldByIndex.
+	Index []int
+	TagOptions
+}
+
+var fieldsCache cache = &sync.Map{}
+
+// ParseTag splits a db struct tag into the column name and its options.
+// Unknown options are ignored.
+func ParseTag(tag string) (string, TagOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	var opts TagOptions
+	for _, option := range strings.Split(rest, ",") {
+		option = strings.TrimSpace(option)
+		if prefix, ok := strings.CutPrefix(option, "prefix="); ok {
+			opts.Prefix = prefix
+			continue
+		}
+		switch option {
+		case "omitempty":
+			opts.OmitEmpty = true
+		case "omitzero":
+			opts.OmitZero = true
+		case "readonly":
+			opts.ReadOnly = true
+		case "autoincrement":
+			opts.AutoIncrement = true
+		case "insertonly":
+			opts.InsertOnly = true
+		case "default":
+			opts.Default = true
+		case "required":
+			opts.Required = true
+		case "json":
+			opts.JSON = true
+		case "dotted":
+			opts.Dotted = true
+		}
+	}
+	return strings.TrimSpace(name), opts
+}
+
+// TaggedFields returns the db tagged fields of the struct type t, including
+// those of nested structs, named as described in NestedPrefix. Fields tagged
+// "-" are left out. The result is computed once per type and must not be
+// modified.
+func TaggedFields(t reflect.Type) []Field {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]Field)
+	}
+	fields := taggedFields(t, nil, "")
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+func taggedFields(t reflect.Type, index []int, prefix string) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if nested, ok := NestedPrefix(field); ok {
+			fields = append(fields, taggedFields(field.Type, fieldIndex, prefix+nested)...)
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(dbTag)
+		if !ok {
+			continue
+		}
+		name, opts := ParseTag(tag)
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, Field{Name: prefix + name, Index: fieldIndex, TagOptions: opts})
+	}
+	return fields
+}
+
+// NestedPrefix reports whether field is a nested struct whose fields map to
+// columns of their own, and the prefix of those columns:
+//
+//	Address  Address                                  // untagged: flattened, no prefix
+//	Guardian Guardian `db:"guardian"`                 // flattened, no prefix
+//	Guardian Guardian `db:"guardian,prefix=guardian_"` // guardian_name, guardian_phone
+//	Guardian Guardian `db:"guardian,dotted"`          // guardian.name, guardian.phone
+//
+// Structs that are column values themselves, such as time.Time, types
+// implementing driver.Valuer or sql.Scanner and fields tagged json, are not
+// nested.
+func NestedPrefix(field reflect.StructField) (string, bool) {
+	if field.Type.Kind() != reflect.Struct || isColumnStruct(field.Type) {
+		return "", false
+	}
+
+	tag, ok := field.Tag.Lookup(dbTag)
+	if !ok {
+		return "", true
+	}
+	name, opts := ParseTag(tag)
+	switch {
+	case name == "-" || opts.JSON:
+		return "", false
+	case opts.Prefix != "":
+		return opts.Prefix, true
+	case opts.Dotted && name != "":
+		return name + ".", true
+	}
+	return "", true
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+func isColumnStruct(t reflect.Type) bool {
+	return isValidSqlValue(reflect.New(t).Elem()) || reflect.PointerTo(t).Implements(scannerType)
+}
@@ -0,0 +1,42 @@
+package sqlscan
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type contact struct {
+	Name  string `db:"name"`
+	Phone string `db:"phone"`
+}
+
+func TestTaggedFieldsNesting(t *testing.T) {
+	type student struct {
+		ID        int64     `db:"id"`
+		Address   contact   // flattened
+		Guardian  contact   `db:"guardian"`
+		Emergency contact   `db:"emergency,prefix=emergency_"`
+		Parent    contact   `db:"parent,dotted"`
+		Skipped   contact   `db:"-"`
+		Profile   contact   `db:"profile,json"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+
+	var names []string
+	for _, field := range TaggedFields(reflect.TypeOf(student{})) {
+		names = append(names, field.Name)
+	}
+	want := []string{
+		"id",
+		"name", "phone",
+		"name", "phone",
+		"emergency_name", "emergency_phone",
+		"parent.name", "parent.phone",
+		"profile",
+		"created_at",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("TaggedFields = %q, want %q", names, want)
+	}
+}
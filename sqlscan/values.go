@@ -10,8 +10,14 @@ var valuesCache cache = &sync.Map{}
 
 // Values scans a struct and returns the values associated with the columns
 // provided. Only simple value types are supported (i.e. Bool, Ints, Uints,
-// Floats, Interface, String)
+// Floats, Interface, String). Value conversions registered with Converters
+// are applied, and fields tagged json are encoded with EncodeJSON.
 func Values(cols []string, v interface{}) ([]interface{}, error) {
+	return defaultScanner(false).Values(cols, v)
+}
+
+// Values is the package function Values using the conversions of s.
+func (s *Scanner) Values(cols []string, v interface{}) ([]interface{}, error) {
 	vals := make([]interface{}, len(cols))
 	model, err := reflectValue(v)
 	if err != nil {
@@ -21,31 +27,45 @@ func Values(cols []string, v interface{}) ([]interface{}, error) {
 	fields := loadFields(model)
 
 	for i, col := range cols {
-		j, ok := fields[col]
+		field, ok := fields[col]
 		if !ok {
 			return nil, fmt.Errorf("field %T.%q either does not exist or is unexported: %w", v, col, ErrStructFieldMissing)
 		}
 
-		vals[i] = model.FieldByIndex(j).Interface()
+		value := model.FieldByIndex(field.index).Interface()
+		if field.json {
+			vals[i], err = EncodeJSON(col, value)
+		} else {
+			vals[i], err = s.converters.value(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %T.%q: %w", v, col, err)
+		}
 	}
 	return vals, nil
 }
 
-func loadFields(val reflect.Value) map[string][]int {
+// valueField locates the field of a column and tells whether it holds JSON.
+type valueField struct {
+	index []int
+	json  bool
+}
+
+func loadFields(val reflect.Value) map[string]valueField {
 	if cache, cached := valuesCache.Load(val.Type()); cached {
-		return cache.(map[string][]int)
+		return cache.(map[string]valueField)
 	}
 	return writeFieldsCache(val)
 }
 
-func writeFieldsCache(val reflect.Value) map[string][]int {
-	m := map[string][]int{}
-	writeFields(val, m, []int{})
+func writeFieldsCache(val reflect.Value) map[string]valueField {
+	m := map[string]valueField{}
+	writeFields(val, m, []int{}, "")
 	valuesCache.Store(val.Type(), m)
 	return m
 }
 
-func writeFields(val reflect.Value, m map[string][]int, index []int) {
+func writeFields(val reflect.Value, m map[string]valueField, index []int, prefix string) {
 	typ := val.Type()
 	numfield := val.NumField()
 
@@ -56,16 +76,17 @@ func writeFields(val reflect.Value, m map[string][]int, index []int) {
 		}
 
 		field := typ.Field(i)
-		fieldIndex := append(index, field.Index...)
+		fieldIndex := append(append([]int{}, index...), field.Index...)
 
-		if field.Type.Kind() == reflect.Struct && !isValidSqlValue(valField) {
-			writeFields(valField, m, fieldIndex)
+		if nested, ok := NestedPrefix(field); ok {
+			writeFields(valField, m, fieldIndex, prefix+nested)
 			continue
 		}
 
-		m[field.Name] = fieldIndex
+		m[prefix+field.Name] = valueField{index: fieldIndex}
 		if tag, ok := field.Tag.Lookup(dbTag); ok {
-			m[tag] = fieldIndex
+			name, opts := ParseTag(tag)
+			m[prefix+name] = valueField{index: fieldIndex, json: opts.JSON}
 		}
 	}
 }